@@ -0,0 +1,193 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudygreybeard/favs/pkg/history"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [<snap-a> <snap-b>]",
+	Short: "Show bookmark changes between syncs",
+	Long: `Compares two recorded syncs and reports bookmarks that were added,
+removed, moved to another folder or retitled.
+
+With history.enabled set in the config, every sync (favs, favs --all,
+...) is recorded in a snapshot history (history.path, default
+~/.favs/history.db). Snapshots are
+grouped by scope: "all" for --all syncs, "<browser>/<profile>" otherwise.
+
+Without arguments, the latest snapshot of the scope is compared with the
+one before it. With --since, it is compared with the latest snapshot at
+least that old. Two snapshot IDs compare those snapshots directly.
+
+Examples:
+  favs diff                       # Since the previous --all sync
+  favs diff --since 7d            # Over the last week
+  favs diff --scope chrome/Default --since 2w
+  favs diff 12 15 --format markdown
+  favs diff --list                # List snapshots`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().String("since", "", "compare with the snapshot from this long ago (e.g. 36h, 7d, 2w)")
+	diffCmd.Flags().String("scope", "all", "snapshot scope: all, or <browser>/<profile>")
+	diffCmd.Flags().String("format", "text", "output format: text, json, or markdown")
+	diffCmd.Flags().Bool("list", false, "list recorded snapshots and exit")
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	store, err := history.Open(cfg.HistoryPath())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if list, _ := cmd.Flags().GetBool("list"); list {
+		return listSnapshots(store)
+	}
+
+	from, to, err := selectSnapshots(cmd, store, args)
+	if err != nil {
+		return err
+	}
+
+	fromColl, err := store.Load(from.ID)
+	if err != nil {
+		return err
+	}
+	toColl, err := store.Load(to.ID)
+	if err != nil {
+		return err
+	}
+
+	d := history.Compare(fromColl, toColl)
+	d.From, d.To = from, to
+
+	format, _ := cmd.Flags().GetString("format")
+	data, err := history.Render(d, format)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+// selectSnapshots picks the snapshots to compare from the arguments.
+func selectSnapshots(cmd *cobra.Command, store *history.Store, args []string) (from, to history.Snapshot, err error) {
+	since, _ := cmd.Flags().GetString("since")
+
+	switch {
+	case len(args) == 1:
+		return from, to, fmt.Errorf("expected two snapshot IDs, got one")
+	case len(args) == 2:
+		if since != "" {
+			return from, to, fmt.Errorf("--since cannot be combined with snapshot IDs")
+		}
+		if from, err = getSnapshot(store, args[0]); err != nil {
+			return from, to, err
+		}
+		to, err = getSnapshot(store, args[1])
+		return from, to, err
+	}
+
+	scope, _ := cmd.Flags().GetString("scope")
+	to, err = store.Latest(scope)
+	if errors.Is(err, history.ErrNotFound) {
+		return from, to, fmt.Errorf("no snapshots for scope %q (run a sync first, or see favs diff --list)", scope)
+	}
+	if err != nil {
+		return from, to, err
+	}
+
+	if since != "" {
+		d, err := parseSince(since)
+		if err != nil {
+			return from, to, err
+		}
+		from, err = store.Before(scope, time.Now().Add(-d))
+		return from, to, err
+	}
+
+	from, err = store.Previous(to)
+	if errors.Is(err, history.ErrNotFound) {
+		return from, to, fmt.Errorf("only one snapshot for scope %q; nothing to compare", scope)
+	}
+	return from, to, err
+}
+
+func getSnapshot(store *history.Store, arg string) (history.Snapshot, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return history.Snapshot{}, fmt.Errorf("invalid snapshot ID: %s", arg)
+	}
+	snap, err := store.Get(id)
+	if errors.Is(err, history.ErrNotFound) {
+		return snap, fmt.Errorf("snapshot %d not found", id)
+	}
+	return snap, err
+}
+
+// parseSince parses a duration, accepting d (days) and w (weeks) in
+// addition to the units of time.ParseDuration.
+func parseSince(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid --since value: %s", s)
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --since value: %s", s)
+	}
+	return d, nil
+}
+
+func listSnapshots(store *history.Store) error {
+	snaps, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(snaps) == 0 {
+		fmt.Println("No snapshots recorded yet.")
+		return nil
+	}
+
+	fmt.Printf("  %-6s %-17s %-24s %s\n", "ID", "Time", "Scope", "Bookmarks")
+	for _, s := range snaps {
+		fmt.Printf("  %-6d %-17s %-24s %d\n", s.ID, s.CreatedAt.Format("2006-01-02 15:04"), s.Scope, s.Count)
+	}
+	return nil
+}
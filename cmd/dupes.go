@@ -0,0 +1,171 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+	"github.com/cloudygreybeard/favs/pkg/pipeline"
+	"github.com/spf13/cobra"
+)
+
+var dupesCmd = &cobra.Command{
+	Use:   "dupes",
+	Short: "Report likely duplicate bookmarks",
+	Long: `Reads bookmarks from all available browsers (or one, with -b) and
+groups bookmarks that probably point at the same page:
+
+  same URL           Identical URLs
+  equivalent URL     The same canonical URL: http/https, www., trailing
+                     slashes, fragments, query order and tracking
+                     parameters (pipeline.transform.canonicalize) aside
+  AMP or mobile URL  The same page under an AMP or m. address
+  similar title      Titles sharing most of their words (Jaccard
+                     similarity of at least --threshold); titles of a
+                     single word are not compared
+
+The configured filters apply; deduplication does not.
+
+Examples:
+  favs dupes
+  favs dupes -b firefox --threshold 0.6
+  favs dupes --format json > dupes.json`,
+	Args: cobra.NoArgs,
+	RunE: runDupes,
+}
+
+func init() {
+	dupesCmd.Flags().StringP("browser", "b", "", "browser or named source to check (default: all available)")
+	dupesCmd.Flags().StringP("profile", "p", "", "profile name (with --browser)")
+	dupesCmd.Flags().Float64("threshold", 0.8, "title similarity from 0 to 1 at which bookmarks are duplicates (0 = ignore titles)")
+	dupesCmd.Flags().String("format", "text", "output format: text or json")
+	dupesCmd.Flags().Bool("diagnostics-json", false, "print diagnostics to stderr as JSON")
+
+	rootCmd.AddCommand(dupesCmd)
+}
+
+func runDupes(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format: %s (available: text, json)", format)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	browserFlag, _ := cmd.Flags().GetString("browser")
+	profileFlag, _ := cmd.Flags().GetString("profile")
+
+	p := newPipeline(cfg)
+	defer reportDiagnostics(cmd, p.Diagnostics())
+
+	collection, err := p.Read(context.Background(), pipeline.ReadOptions{
+		All:     browserFlag == "",
+		Input:   browserFlag,
+		Profile: profileFlag,
+	})
+	if err != nil {
+		return err
+	}
+	collection = p.Filter(collection)
+
+	canon := cfg.Pipeline.Transform.Canonicalize
+	threshold, _ := cmd.Flags().GetFloat64("threshold")
+	clusters := bookmark.FindDuplicates(collection.Bookmarks, bookmark.DuplicateOptions{
+		Canonicalizer:  bookmark.Canonicalizer{StripParams: canon.StripParams},
+		TitleThreshold: threshold,
+		MinTitleWords:  2,
+	})
+	logVerbose("Duplicates: %d clusters in %d bookmarks", len(clusters), collection.Count())
+
+	if format == "json" {
+		return writeDupesJSON(os.Stdout, clusters)
+	}
+	writeDupesText(os.Stdout, clusters)
+	return nil
+}
+
+// writeDupesText lists each cluster with its reasons and bookmarks.
+func writeDupesText(w io.Writer, clusters []bookmark.DuplicateCluster) {
+	if len(clusters) == 0 {
+		fmt.Fprintln(w, "No likely duplicates found.")
+		return
+	}
+
+	total := 0
+	for _, c := range clusters {
+		total += len(c.Bookmarks)
+	}
+	fmt.Fprintf(w, "%d groups of likely duplicates (%d bookmarks)\n", len(clusters), total)
+
+	for i, c := range clusters {
+		fmt.Fprintf(w, "\n%d. %s\n", i+1, strings.Join(c.Reasons, ", "))
+		for _, b := range c.Bookmarks {
+			title := b.Title
+			if title == "" {
+				title = b.URL
+			}
+			source := b.Source
+			if b.Profile != "" {
+				source += "/" + bookmark.ProfileLabel(b.Profile, b.ProfileName)
+			}
+			if len(b.FolderPath) > 0 {
+				source += ": " + strings.Join(b.FolderPath, "/")
+			}
+			fmt.Fprintf(w, "   %s\n     %s\n     %s\n", title, b.URL, source)
+		}
+	}
+}
+
+type dupeCluster struct {
+	Reasons   []string    `json:"reasons"`
+	Bookmarks []dupeEntry `json:"bookmarks"`
+}
+
+type dupeEntry struct {
+	Title       string   `json:"title"`
+	URL         string   `json:"url"`
+	Folder      []string `json:"folder,omitempty"`
+	Source      string   `json:"source"`
+	Profile     string   `json:"profile,omitempty"`
+	ProfileName string   `json:"profile_name,omitempty"`
+}
+
+// writeDupesJSON writes the clusters as a JSON array.
+func writeDupesJSON(w io.Writer, clusters []bookmark.DuplicateCluster) error {
+	out := make([]dupeCluster, 0, len(clusters))
+	for _, c := range clusters {
+		dc := dupeCluster{Reasons: c.Reasons}
+		for _, b := range c.Bookmarks {
+			dc.Bookmarks = append(dc.Bookmarks, dupeEntry{
+				Title: b.Title, URL: b.URL, Folder: b.FolderPath,
+				Source: b.Source, Profile: b.Profile, ProfileName: b.ProfileName,
+			})
+		}
+		out = append(out, dc)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+)
+
+// explainFilters writes the filter audit for --explain-filters: which
+// rule excluded each bookmark, how often each rule fired, and which
+// rules excluded nothing.
+func explainFilters(w io.Writer, total int, opts bookmark.FilterOptions, result bookmark.FilterResult) {
+	fmt.Fprintf(w, "Excluded %d of %d bookmarks.\n", len(result.Exclusions), total)
+
+	for _, msg := range result.Errors {
+		fmt.Fprintf(w, "Error: %s\n", msg)
+	}
+
+	if len(result.Exclusions) > 0 {
+		fmt.Fprintln(w)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "RULE\tSOURCE\tFOLDER\tBOOKMARK\tREASON")
+		for _, e := range result.Exclusions {
+			b := e.Bookmark
+			source := b.Source
+			if b.Profile != "" {
+				source += "/" + bookmark.ProfileLabel(b.Profile, b.ProfileName)
+			}
+			title := b.Title
+			if title == "" {
+				title = b.URL
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+				e.Rule, source, strings.Join(b.FolderPath, "/"), clip(title, 50), e.Reason)
+		}
+		tw.Flush()
+	}
+
+	counts := make(map[string]int)
+	for _, e := range result.Exclusions {
+		counts[e.Rule]++
+	}
+
+	rules := opts.RuleIDs()
+	if len(rules) == 0 {
+		fmt.Fprintln(w, "\nNo exclusion rules are configured.")
+		return
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RULE\tEXCLUDED")
+	var unused []string
+	for _, rule := range rules {
+		fmt.Fprintf(tw, "%s\t%d\n", rule, counts[rule])
+		if counts[rule] == 0 {
+			unused = append(unused, rule)
+		}
+	}
+	tw.Flush()
+
+	if len(unused) > 0 {
+		fmt.Fprintln(w, "\nRules that excluded nothing:")
+		for _, rule := range unused {
+			fmt.Fprintf(w, "  %s\n", rule)
+		}
+	}
+}
+
+// clip shortens s to at most n runes.
+func clip(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
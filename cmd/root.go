@@ -64,6 +64,8 @@ Examples:
   favs --all                     # All browsers and profiles
   favs --format json             # JSON output
   favs --style table             # Markdown table format
+  favs search tag:go             # Search bookmarks
+  favs diff --since 7d           # Changes over the last week
   favs serve                     # Run as MCP server
   favs adapters                  # List available adapters
   favs --list                    # List available browsers/profiles`,
@@ -82,16 +84,19 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output to stderr")
 
 	rootCmd.Flags().StringP("output", "o", "", "output file (default: stdout)")
-	rootCmd.Flags().StringP("browser", "b", "", "browser to use (default: first available)")
+	rootCmd.Flags().StringP("browser", "b", "", "browser or named source to use (default: first available)")
 	rootCmd.Flags().StringP("profile", "p", "", "profile name (default: Default or first found)")
 	rootCmd.Flags().Bool("all", false, "read from all available browsers and profiles")
 	rootCmd.Flags().Bool("group", true, "group bookmarks by browser (with --all)")
 	rootCmd.Flags().Bool("metadata", true, "include metadata header")
 	rootCmd.Flags().Bool("nested", true, "use nested list format (textual style)")
 	rootCmd.Flags().Bool("sort", false, "sort alphabetically")
-	rootCmd.Flags().Bool("list", false, "list available browser profiles and exit")
+	rootCmd.Flags().Bool("list", false, "list browser profiles and named sources and exit")
 	rootCmd.Flags().String("style", "textual", "output style: textual, table, or yaml (markdown only)")
 	rootCmd.Flags().String("format", "markdown", "output format: markdown, json, or yaml")
+	rootCmd.Flags().Bool("no-history", false, "don't record this sync in the snapshot history")
+	rootCmd.Flags().Bool("diagnostics-json", false, "print diagnostics to stderr as JSON")
+	rootCmd.Flags().Bool("explain-filters", false, "report which filter rule excluded each bookmark, instead of syncing")
 
 	// URL protocol filtering flags
 	rootCmd.Flags().StringSlice("exclude-protocols", nil, "protocols to exclude (e.g., data,javascript)")
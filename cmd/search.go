@@ -0,0 +1,186 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+	"github.com/cloudygreybeard/favs/pkg/diag"
+	"github.com/cloudygreybeard/favs/pkg/index"
+	"github.com/cloudygreybeard/favs/pkg/pipeline"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search bookmarks",
+	Long: `Searches bookmarks from all available browsers (or one, with -b)
+and prints the matches, most relevant first.
+
+With index.enabled set in the config, searches of all browsers use an
+on-disk index (index.path, default ~/.favs/index) that is updated
+whenever a browser's bookmark files change. The index matches words by prefix and tolerates small
+typos; --no-index searches by substring instead.
+
+Query syntax (all terms must match):
+  go tutorial             Free text in title, URL, folders, tags or description
+  "exact phrase"          Quoted free text
+  -term                   Exclude matches (works with fields too)
+  tag:go                  Has tag
+  folder:Dev/Tools        In folder (consecutive folder names)
+  site:github.com         Host is the domain or a subdomain
+  title:...  url:...      Substring of title or URL
+  source:chrome           From input adapter
+  profile:Work            From profile, by directory or given name
+  added:>2025-01-01       Date added (>, >=, <, <=; YYYY, YYYY-MM or YYYY-MM-DD)
+
+Examples:
+  favs search kubernetes
+  favs search 'tag:go site:github.com -archived'
+  favs search 'folder:"Bookmarks Bar/Dev" added:>=2025' --format json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().StringP("browser", "b", "", "browser to search (default: all available)")
+	searchCmd.Flags().StringP("profile", "p", "", "profile name (requires --browser)")
+	searchCmd.Flags().Int("limit", 20, "maximum results (0 = all)")
+	searchCmd.Flags().Int("offset", 0, "results to skip")
+	searchCmd.Flags().String("format", "", "render results with an output adapter (default: plain list)")
+	searchCmd.Flags().Bool("no-index", false, "search without the on-disk index")
+	searchCmd.Flags().Bool("diagnostics-json", false, "print diagnostics to stderr as JSON")
+	searchCmd.Flags().Bool("reindex", false, "rebuild the on-disk index before searching")
+
+	rootCmd.AddCommand(searchCmd)
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	query, err := bookmark.ParseQuery(strings.Join(args, " "))
+	if err != nil {
+		return fmt.Errorf("parsing query: %w", err)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	browserFlag, _ := cmd.Flags().GetString("browser")
+	profileFlag, _ := cmd.Flags().GetString("profile")
+	if profileFlag != "" && browserFlag == "" {
+		return fmt.Errorf("--profile requires --browser")
+	}
+
+	limit, _ := cmd.Flags().GetInt("limit")
+	offset, _ := cmd.Flags().GetInt("offset")
+	opts := bookmark.SearchOptions{Limit: limit, Offset: offset}
+
+	p := newPipeline(cfg)
+	defer reportDiagnostics(cmd, p.Diagnostics())
+	ctx := context.Background()
+
+	var collection *bookmark.Collection
+	var results []bookmark.SearchResult
+	var total int
+
+	// The index covers all inputs; searches of one browser read it directly
+	var ix *index.Index
+	noIndex, _ := cmd.Flags().GetBool("no-index")
+	if cfg.Index.Enabled && !noIndex && browserFlag == "" {
+		ix = syncIndex(cmd, ctx, p, cfg.IndexPath())
+	}
+	if ix != nil {
+		collection = ix.Collection()
+		results, total = ix.Search(query, opts)
+	} else {
+		collection, err = p.Run(ctx, pipeline.ReadOptions{
+			All:     browserFlag == "",
+			Input:   browserFlag,
+			Profile: profileFlag,
+		})
+		if err != nil {
+			return err
+		}
+		results, total = bookmark.Search(collection.Bookmarks, query, opts)
+	}
+	logVerbose("Matches: %d of %d bookmarks", total, collection.Count())
+
+	if format, _ := cmd.Flags().GetString("format"); format != "" {
+		matched := bookmark.NewCollection()
+		counts := make(map[string]int)
+		for _, r := range results {
+			matched.Bookmarks = append(matched.Bookmarks, r.Bookmark)
+			counts[r.Bookmark.Source]++
+		}
+		for _, src := range collection.Sources {
+			if counts[src.ID()] > 0 {
+				src.Count = counts[src.ID()]
+				matched.Sources = append(matched.Sources, src)
+			}
+		}
+		renderOpts := p.RenderOptions()
+		renderOpts.SortAlpha = false
+		data, err := p.Render(matched, format, renderOpts)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+
+	for _, r := range results {
+		b := r.Bookmark
+		title := b.Title
+		if title == "" {
+			title = b.URL
+		}
+		fmt.Println(title)
+		fmt.Printf("  %s\n", b.URL)
+		if len(b.FolderPath) > 0 {
+			fmt.Printf("  %s\n", strings.Join(b.FolderPath, "/"))
+		}
+	}
+	if len(results) < total {
+		fmt.Fprintf(os.Stderr, "\n%d of %d matches shown (use --limit and --offset for more)\n", len(results), total)
+	}
+	return nil
+}
+
+// syncIndex opens the search index in path and brings it up to date.
+// If that fails it records a warning and returns nil, and the search
+// reads the bookmarks instead.
+func syncIndex(cmd *cobra.Command, ctx context.Context, p *pipeline.Pipeline, path string) *index.Index {
+	ix, err := index.Open(path)
+	if err == nil {
+		if reindex, _ := cmd.Flags().GetBool("reindex"); reindex {
+			ix.Reset()
+		}
+		var updated int
+		if updated, err = index.Sync(ctx, ix, p); err == nil {
+			logVerbose("Index: %s (%d bookmarks, %d sources re-read)", path, ix.Len(), updated)
+			return ix
+		}
+	}
+	p.Diagnostics().Add(diag.Diagnostic{
+		Severity: diag.Warning,
+		Message:  fmt.Sprintf("search index unavailable, searching without it: %v", err),
+	})
+	return nil
+}
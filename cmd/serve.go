@@ -20,6 +20,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/cloudygreybeard/favs/pkg/mcp"
 	"github.com/spf13/cobra"
@@ -30,16 +31,39 @@ var serveCmd = &cobra.Command{
 	Short: "Run as an MCP server",
 	Long: `Runs favs as an MCP (Model Context Protocol) server.
 
-The server communicates via JSON-RPC over stdin/stdout, exposing:
+By default the server communicates via JSON-RPC over stdin/stdout.
+With --transport http it serves the MCP Streamable HTTP transport
+instead, so several clients can share one long-running instance.
+
+The server exposes:
 
 Resources:
   - favs://all        All bookmarks in JSON format
   - favs://markdown   All bookmarks in Markdown format
   - favs://<browser>  Bookmarks from a specific browser
 
+Resource templates:
+  - favs://{browser}/{profile}  Bookmarks from one browser profile
+  - favs://folder{/path*}       Bookmarks in a folder (e.g. Bookmarks Bar/Dev)
+  - favs://tag/{tag}            Bookmarks with a tag
+
+Any resource accepts ?format=<name> to select an output adapter
+(json, markdown, yaml, opml, html, ...).
+
 Tools:
   - sync_bookmarks      Refresh bookmarks from browsers
-  - search_bookmarks    Search bookmarks by title or URL
+  - search_bookmarks    Search bookmarks (same query language as favs search)
+  - get_diagnostics     Read errors and filter problems from the last load
+
+Bookmarks pass through the same filter and transform pipeline as the
+CLI. Filter warnings and read errors are sent to the client as MCP
+logging notifications (see logging/setLevel).
+
+The server watches the browsers' bookmark files. When one changes it
+drops its cached bookmarks, sends notifications/resources/list_changed,
+and sends notifications/resources/updated for every resource a client
+has subscribed to (resources/subscribe). Use --watch-interval 0 to
+disable watching.
 
 Usage with Claude Desktop or similar MCP clients:
 
@@ -52,11 +76,23 @@ Add to your MCP configuration:
         "args": ["serve"]
       }
     }
-  }`,
+  }
+
+Shared HTTP server (clients connect to http://127.0.0.1:8080/mcp):
+
+  favs serve --transport http --addr 127.0.0.1:8080 --token "$TOKEN"`,
 	RunE: runServe,
 }
 
 func init() {
+	serveCmd.Flags().String("transport", "stdio", "transport: stdio or http")
+	serveCmd.Flags().String("addr", "127.0.0.1:8080", "listen address (http transport)")
+	serveCmd.Flags().String("path", "/mcp", "endpoint path (http transport)")
+	serveCmd.Flags().String("token", "", "bearer token required by the http transport (default: $FAVS_MCP_TOKEN)")
+	serveCmd.Flags().Duration("session-timeout", 30*time.Minute, "close http sessions idle for this long")
+	serveCmd.Flags().StringSlice("allow-origin", nil, "browser origin allowed besides localhost, e.g. https://app.example.com (http transport, repeatable)")
+	serveCmd.Flags().Duration("watch-interval", 2*time.Second, "how often to check bookmark files for changes (0 disables)")
+
 	rootCmd.AddCommand(serveCmd)
 }
 
@@ -80,6 +116,33 @@ func runServe(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
-	fmt.Fprintln(os.Stderr, "favs MCP server started")
-	return server.Run(ctx)
+	if interval, _ := cmd.Flags().GetDuration("watch-interval"); interval > 0 {
+		go server.Watch(ctx, interval)
+	}
+
+	transport, _ := cmd.Flags().GetString("transport")
+	switch transport {
+	case "stdio":
+		fmt.Fprintln(os.Stderr, "favs MCP server started")
+		return server.Run(ctx)
+	case "http":
+		addr, _ := cmd.Flags().GetString("addr")
+		path, _ := cmd.Flags().GetString("path")
+		token, _ := cmd.Flags().GetString("token")
+		if token == "" {
+			token = os.Getenv("FAVS_MCP_TOKEN")
+		}
+		timeout, _ := cmd.Flags().GetDuration("session-timeout")
+		origins, _ := cmd.Flags().GetStringSlice("allow-origin")
+		fmt.Fprintf(os.Stderr, "favs MCP server listening on http://%s%s\n", addr, path)
+		return server.RunHTTP(ctx, mcp.HTTPOptions{
+			Addr:           addr,
+			Path:           path,
+			Token:          token,
+			SessionTimeout: timeout,
+			AllowedOrigins: origins,
+		})
+	default:
+		return fmt.Errorf("unknown transport: %s (available: stdio, http)", transport)
+	}
 }
@@ -16,21 +16,23 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/cloudygreybeard/favs/pkg/adapter"
 	"github.com/cloudygreybeard/favs/pkg/bookmark"
 	"github.com/cloudygreybeard/favs/pkg/config"
+	"github.com/cloudygreybeard/favs/pkg/diag"
+	"github.com/cloudygreybeard/favs/pkg/history"
 	"github.com/cloudygreybeard/favs/pkg/input"
-	"github.com/cloudygreybeard/favs/pkg/output"
+	"github.com/cloudygreybeard/favs/pkg/pipeline"
 	"github.com/spf13/cobra"
 )
 
-// Input adapter preference order
-var inputPreference = []string{"chrome", "firefox", "edge", "safari", "chromium", "brave"}
-
 func runSync(cmd *cobra.Command, args []string) error {
 	// Check for list mode
 	if list, _ := cmd.Flags().GetBool("list"); list {
@@ -51,100 +53,47 @@ func runSync(cmd *cobra.Command, args []string) error {
 	browserFlag, _ := cmd.Flags().GetString("browser")
 	profileFlag, _ := cmd.Flags().GetString("profile")
 
-	// Collect bookmarks
-	collection := bookmark.NewCollection()
+	p := newPipeline(cfg)
+	defer reportDiagnostics(cmd, p.Diagnostics())
 	ctx := context.Background()
 
-	if allMode {
-		// Read from all available inputs
-		if err := readAllInputs(ctx, cfg, collection); err != nil {
-			return err
-		}
-	} else {
-		// Read from preferred/specified input
-		if err := readPreferredInput(ctx, cfg, browserFlag, profileFlag, collection); err != nil {
-			return err
-		}
+	// Collect bookmarks
+	collection, err := p.Read(ctx, pipeline.ReadOptions{
+		All:     allMode,
+		Input:   browserFlag,
+		Profile: profileFlag,
+	})
+	if err != nil {
+		return err
 	}
 
 	if collection.Count() == 0 {
 		return fmt.Errorf("no bookmarks found")
 	}
 
-	// Build filter options from config and flags
-	filterOpts := bookmark.FilterOptions{
-		IncludeFolders:     cfg.Pipeline.Filter.IncludeFolders,
-		ExcludeFolders:     cfg.Pipeline.Filter.ExcludeFolders,
-		ExcludeURLPatterns: cfg.Pipeline.Filter.ExcludeURLPatterns,
-		ExcludeProtocols:   cfg.Pipeline.Filter.ExcludeProtocols,
-		WarnProtocols:      cfg.Pipeline.Filter.WarnProtocols,
-		MaxURLLength:       cfg.Pipeline.Filter.MaxURLLength,
-		WarnURLLength:      cfg.Pipeline.Filter.WarnURLLength,
-	}
-
-	// Apply flag overrides for protocol filtering
-	if excludeProtos, _ := cmd.Flags().GetStringSlice("exclude-protocols"); len(excludeProtos) > 0 {
-		filterOpts.ExcludeProtocols = excludeProtos
-	}
-	if warnProtos, _ := cmd.Flags().GetStringSlice("warn-protocols"); len(warnProtos) > 0 {
-		filterOpts.WarnProtocols = warnProtos
-	}
-	if maxLen, _ := cmd.Flags().GetInt("max-url-length"); maxLen > 0 {
-		filterOpts.MaxURLLength = maxLen
-	}
-	if warnLen, _ := cmd.Flags().GetInt("warn-url-length"); warnLen > 0 {
-		filterOpts.WarnURLLength = warnLen
-	}
-
-	// Apply filters
-	filterResult := bookmark.Filter(collection.Bookmarks, filterOpts)
-
-	// Log warnings
-	for _, w := range filterResult.Warnings {
-		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
-	}
-
-	if filterResult.Excluded > 0 {
-		logVerbose("Excluded %d bookmarks by filter rules", filterResult.Excluded)
+	if explain, _ := cmd.Flags().GetBool("explain-filters"); explain {
+		opts := p.FilterOptions()
+		explainFilters(os.Stdout, collection.Count(), opts, bookmark.Filter(collection.Bookmarks, opts))
+		return nil
 	}
 
-	filtered := filterResult.Bookmarks
-	if cfg.Pipeline.Transform.Deduplicate {
-		filtered = bookmark.Deduplicate(filtered)
-	}
-
-	// Update collection with filtered bookmarks
-	filteredCollection := &bookmark.Collection{
-		Bookmarks: filtered,
-		Sources:   collection.Sources,
-	}
+	// Apply filters and transformations
+	filteredCollection := p.Transform(p.Filter(collection))
 
 	logVerbose("Source: %s", formatSources(collection.Sources, allMode))
-	logVerbose("Bookmarks: %d", len(filtered))
-
-	// Get output adapter
-	outputFormat, _ := cmd.Flags().GetString("format")
-	outAdapter, ok := adapter.GetOutput(outputFormat)
-	if !ok {
-		return fmt.Errorf("unknown output format: %s (available: %v)", outputFormat, adapter.ListOutputs())
-	}
+	logVerbose("Bookmarks: %d", filteredCollection.Count())
 
 	// Build render options
+	outputFormat, _ := cmd.Flags().GetString("format")
 	style, _ := cmd.Flags().GetString("style")
-	renderOpts := output.RenderOptions{
-		IncludeMetadata: cfg.Pipeline.Render.IncludeMetadata,
-		IncludeDates:    cfg.Pipeline.Render.IncludeDates,
-		IncludeTags:     cfg.Pipeline.Render.IncludeTags,
-		IncludeProfile:  cfg.Pipeline.Render.IncludeProfile,
-		GroupBySource:   allMode && cfg.Pipeline.Render.GroupBySource,
-		SortAlpha:       cfg.Pipeline.Transform.Sort,
-		Style:           style,
-	}
+	renderOpts := p.RenderOptions()
+	renderOpts.GroupBySource = allMode && renderOpts.GroupBySource
+	renderOpts.Style = style
 
 	// Render output
-	data, err := outAdapter.Render(filteredCollection, renderOpts)
+	data, err := p.Render(filteredCollection, outputFormat, renderOpts)
 	if err != nil {
-		return fmt.Errorf("rendering output: %w", err)
+		return err
 	}
 
 	// Write output
@@ -161,162 +110,148 @@ func runSync(cmd *cobra.Command, args []string) error {
 		logVerbose("Written to: %s", outPath)
 	}
 
+	// Record the sync for favs diff; a failure here shouldn't fail the sync
+	if noHistory, _ := cmd.Flags().GetBool("no-history"); cfg.History.Enabled && !noHistory {
+		scope := history.Scope(allMode, collection.Sources)
+		if err := recordSnapshot(cfg, filteredCollection, scope); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
 	return nil
 }
 
-func readPreferredInput(ctx context.Context, cfg config.Config, browserFlag, profileFlag string, collection *bookmark.Collection) error {
-	var targetInput input.Adapter
+// recordSnapshot saves a collection to the history store and prunes
+// old snapshots of the same scope.
+func recordSnapshot(cfg config.Config, collection *bookmark.Collection, scope string) error {
+	store, err := history.Open(cfg.HistoryPath())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
 
-	if browserFlag != "" {
-		// Specific browser requested
-		inp, ok := adapter.GetInput(browserFlag)
-		if !ok {
-			return fmt.Errorf("unknown browser: %s", browserFlag)
-		}
-		targetInput = inp
-	} else {
-		// Find first available by preference
-		for _, name := range inputPreference {
-			inp, ok := adapter.GetInput(name)
-			if !ok {
-				continue
-			}
-			inputCfg := cfg.GetInputConfig(name)
-			if !inputCfg.Enabled {
-				continue
+	snap, err := store.Save(collection, scope, time.Now())
+	if err != nil {
+		return err
+	}
+	logVerbose("Snapshot: %d (%s)", snap.ID, scope)
+
+	return store.Prune(scope, cfg.History.MaxSnapshots)
+}
+
+// newPipeline creates a pipeline that reports progress to stderr.
+func newPipeline(cfg config.Config) *pipeline.Pipeline {
+	p := pipeline.New(cfg)
+	p.Hooks = pipeline.Hooks{
+		BeforeRead: func(inp input.Adapter) {
+			logVerbose("Browser %s: reading from %s", inp.Name(), inp.Path())
+		},
+		AfterReadDone: func(inp input.Adapter, bookmarks []bookmark.Bookmark, elapsed time.Duration, err error) {
+			if err != nil {
+				logVerbose("Browser %s: error - %v (%s)", inp.Name(), err, elapsed.Round(time.Microsecond))
+				return
 			}
-			if inp.Available() {
-				targetInput = inp
-				break
+			logVerbose("Browser %s: %d bookmarks (%s)", inp.Name(), len(bookmarks), elapsed.Round(time.Microsecond))
+		},
+		AfterFilter: func(result bookmark.FilterResult) {
+			if result.Excluded > 0 {
+				logVerbose("Excluded %d bookmarks by filter rules", result.Excluded)
 			}
-		}
+		},
 	}
+	return p
+}
 
-	if targetInput == nil {
-		return fmt.Errorf("no available browser found")
+// reportDiagnostics prints a pipeline's diagnostics to stderr: errors
+// and warnings, plus info under -v, or all of them as JSON with
+// --diagnostics-json.
+func reportDiagnostics(cmd *cobra.Command, diags *diag.Diagnostics) {
+	if asJSON, _ := cmd.Flags().GetBool("diagnostics-json"); asJSON {
+		all := diags.All()
+		if all == nil {
+			all = []diag.Diagnostic{}
+		}
+		data, err := json.MarshalIndent(all, "", "  ")
+		if err == nil {
+			fmt.Fprintln(os.Stderr, string(data))
+		}
+		return
 	}
 
-	// Configure the input adapter
-	inputCfg := cfg.GetInputConfig(targetInput.Name())
-	if profileFlag != "" {
-		inputCfg.Profile = profileFlag
-	} else if inputCfg.Profile == "" {
-		inputCfg.Profile = "Default"
+	min := diag.Warning
+	if verbose {
+		min = diag.Info
 	}
-
-	if err := targetInput.Configure(input.Config{
-		Enabled:    true,
-		Profile:    inputCfg.Profile,
-		CustomPath: inputCfg.CustomPath,
-	}); err != nil {
-		return fmt.Errorf("configuring %s: %w", targetInput.Name(), err)
+	for _, d := range diags.AtLeast(min) {
+		label := strings.ToUpper(string(d.Severity[:1])) + string(d.Severity[1:])
+		fmt.Fprintf(os.Stderr, "%s: %s\n", label, d)
 	}
-
-	logVerbose("Browser %s: reading from %s", targetInput.Name(), targetInput.Path())
-
-	bookmarks, err := targetInput.Read(ctx)
-	if err != nil {
-		return fmt.Errorf("reading from %s: %w", targetInput.Name(), err)
+	if summary := diags.Summary(); summary != "" {
+		logVerbose("Diagnostics: %s", summary)
 	}
-
-	collection.Add(bookmarks, bookmark.SourceInfo{
-		Name:    targetInput.Name(),
-		Profile: inputCfg.Profile,
-		Path:    targetInput.Path(),
-	})
-
-	return nil
 }
 
-func readAllInputs(ctx context.Context, cfg config.Config, collection *bookmark.Collection) error {
-	for _, name := range inputPreference {
-		inp, ok := adapter.GetInput(name)
-		if !ok {
-			continue
-		}
+func runListProfiles(cmd *cobra.Command) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
 
-		inputCfg := cfg.GetInputConfig(name)
-		if !inputCfg.Enabled {
-			continue
-		}
+	fmt.Println("Available browser profiles:")
+	fmt.Println()
 
-		if !inp.Available() {
+	// Every browser, available or not, then the named sources
+	p := pipeline.New(cfg)
+	for _, name := range pipeline.InputPreference {
+		if _, ok := cfg.GetSource(name); ok {
 			continue
 		}
-
-		// Configure without specific profile to get all
-		if err := inp.Configure(input.Config{
-			Enabled:    true,
-			Profile:    "", // Empty = read all profiles
-			CustomPath: inputCfg.CustomPath,
-		}); err != nil {
-			logVerbose("Browser %s: config error - %v", name, err)
-			continue
+		inp, err := p.Input(name)
+		if err != nil {
+			// Not configurable here; show where it would be read from
+			var ok bool
+			if inp, ok = adapter.GetInput(name); !ok {
+				continue
+			}
 		}
-
-		logVerbose("Browser %s: reading from %s", name, inp.Path())
-
-		bookmarks, err := inp.Read(ctx)
+		printInput(inp, inp.DisplayName())
+	}
+	for _, src := range cfg.Sources {
+		inp, err := p.Input(src.Name)
 		if err != nil {
-			logVerbose("Browser %s: error - %v", name, err)
+			fmt.Printf("  %s (not available: %v)\n\n", src.Name, err)
 			continue
 		}
-
-		if len(bookmarks) > 0 {
-			logVerbose("Browser %s: %d bookmarks", name, len(bookmarks))
-
-			// Determine profile from bookmarks
-			profile := ""
-			if len(bookmarks) > 0 {
-				profile = bookmarks[0].Profile
-			}
-
-			collection.Add(bookmarks, bookmark.SourceInfo{
-				Name:    name,
-				Profile: profile,
-				Path:    inp.Path(),
-			})
-		}
+		printInput(inp, fmt.Sprintf("%s [%s]", src.Name, inp.DisplayName()))
 	}
 
 	return nil
 }
 
-func runListProfiles(cmd *cobra.Command) error {
-	fmt.Println("Available browser profiles:")
-	fmt.Println()
+// printInput prints an input's status, path and profiles for --list.
+func printInput(inp input.Adapter, label string) {
+	status := "not available"
+	if inp.Available() {
+		status = "available"
+	}
 
-	for _, name := range inputPreference {
-		inp, ok := adapter.GetInput(name)
-		if !ok {
-			continue
-		}
+	fmt.Printf("  %s (%s)\n", label, status)
+	fmt.Printf("    Path: %s\n", inp.Path())
 
-		status := "not available"
-		if inp.Available() {
-			status = "available"
-		}
-
-		fmt.Printf("  %s (%s)\n", inp.DisplayName(), status)
-		fmt.Printf("    Path: %s\n", inp.Path())
-
-		if inp.Available() {
-			profiles, err := inp.ListProfiles()
-			if err == nil && len(profiles) > 0 {
-				fmt.Printf("    Profiles:\n")
-				for _, p := range profiles {
-					def := ""
-					if p.IsDefault {
-						def = " (default)"
-					}
-					fmt.Printf("      - %s%s\n", p.Name, def)
+	if inp.Available() {
+		profiles, err := inp.ListProfiles()
+		if err == nil && len(profiles) > 0 {
+			fmt.Printf("    Profiles:\n")
+			for _, p := range profiles {
+				def := ""
+				if p.IsDefault {
+					def = " (default)"
 				}
+				fmt.Printf("      - %s%s\n", bookmark.ProfileLabel(p.Name, p.DisplayName), def)
 			}
 		}
-		fmt.Println()
 	}
-
-	return nil
+	fmt.Println()
 }
 
 func formatSources(sources []bookmark.SourceInfo, allMode bool) string {
@@ -327,10 +262,22 @@ func formatSources(sources []bookmark.SourceInfo, allMode bool) string {
 		return "none"
 	}
 	s := sources[0]
-	return fmt.Sprintf("%s / %s", s.Name, s.Profile)
+	return fmt.Sprintf("%s / %s", s.ID(), bookmark.ProfileLabel(s.Profile, s.ProfileName))
 }
 
 func applyFlagOverrides(cmd *cobra.Command, cfg *config.Config) {
+	if excludeProtos, _ := cmd.Flags().GetStringSlice("exclude-protocols"); len(excludeProtos) > 0 {
+		cfg.Pipeline.Filter.ExcludeProtocols = excludeProtos
+	}
+	if warnProtos, _ := cmd.Flags().GetStringSlice("warn-protocols"); len(warnProtos) > 0 {
+		cfg.Pipeline.Filter.WarnProtocols = warnProtos
+	}
+	if maxLen, _ := cmd.Flags().GetInt("max-url-length"); maxLen > 0 {
+		cfg.Pipeline.Filter.MaxURLLength = maxLen
+	}
+	if warnLen, _ := cmd.Flags().GetInt("warn-url-length"); warnLen > 0 {
+		cfg.Pipeline.Filter.WarnURLLength = warnLen
+	}
 	if cmd.Flags().Changed("metadata") {
 		cfg.Pipeline.Render.IncludeMetadata, _ = cmd.Flags().GetBool("metadata")
 	}
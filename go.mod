@@ -5,6 +5,7 @@ go 1.22
 require (
 	github.com/mattn/go-sqlite3 v1.14.22
 	github.com/spf13/cobra v1.8.0
+	golang.org/x/net v0.21.0
 	golang.org/x/text v0.14.0
 	gopkg.in/yaml.v3 v3.0.1
 	howett.net/plist v1.0.1
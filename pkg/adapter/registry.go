@@ -26,6 +26,7 @@ import (
 var (
 	inputsMu  sync.RWMutex
 	inputs    = make(map[string]input.Adapter)
+	factories = make(map[string]InputFactory)
 	outputsMu sync.RWMutex
 	outputs   = make(map[string]output.Adapter)
 )
@@ -37,6 +38,34 @@ func RegisterInput(adapter input.Adapter) {
 	inputs[adapter.Name()] = adapter
 }
 
+// InputFactory creates a new, unconfigured input adapter.
+type InputFactory func() input.Adapter
+
+// RegisterInputFactory registers an input adapter by its factory. The
+// registry keeps one instance for GetInput and the listing functions;
+// NewInput uses the factory to create independent instances, so several
+// configured sources can share an adapter type.
+func RegisterInputFactory(factory InputFactory) {
+	a := factory()
+	inputsMu.Lock()
+	defer inputsMu.Unlock()
+	inputs[a.Name()] = a
+	factories[a.Name()] = factory
+}
+
+// NewInput returns a new instance of an input adapter. Adapters
+// registered without a factory return their shared instance.
+func NewInput(name string) (input.Adapter, bool) {
+	inputsMu.RLock()
+	factory, ok := factories[name]
+	shared := inputs[name]
+	inputsMu.RUnlock()
+	if ok {
+		return factory(), true
+	}
+	return shared, shared != nil
+}
+
 // RegisterOutput registers an output adapter.
 func RegisterOutput(adapter output.Adapter) {
 	outputsMu.Lock()
@@ -88,9 +88,26 @@ type Bookmark struct {
 	// Examples: "Default", "Profile 1", "work@example.com"
 	Profile string
 
+	// ProfileName is the name the user gave the profile, where the
+	// source keeps one apart from Profile (e.g., "Work" for "Profile 1").
+	ProfileName string
+
 	// Tags are labels or categories assigned to the bookmark.
 	// Not all sources support tags (Firefox does, Chrome doesn't).
 	Tags []string
+
+	// GUID is the source's stable identifier for the bookmark, if any.
+	GUID string
+
+	// Keyword is the address bar shortcut for the bookmark (Firefox).
+	Keyword string
+
+	// Description is the note stored with the bookmark, if any.
+	Description string
+
+	// Occurrences lists every place a merged bookmark was found, its
+	// own first. Empty unless duplicates were merged (see Merge).
+	Occurrences []Occurrence
 }
 
 // Collection is a set of bookmarks aggregated from one or more sources.
@@ -110,9 +127,17 @@ type SourceInfo struct {
 	// Name is the adapter identifier (e.g., "chrome", "firefox").
 	Name string
 
+	// Instance is the name of the configured source instance, for
+	// sources defined under sources: in the config. Bookmarks from an
+	// instance carry this name as their Source.
+	Instance string
+
 	// Profile is the profile/account within the source.
 	Profile string
 
+	// ProfileName is the name the user gave the profile, if any.
+	ProfileName string
+
 	// Path is the filesystem path or URI that was read.
 	Path string
 
@@ -120,6 +145,27 @@ type SourceInfo struct {
 	Count int
 }
 
+// ProfileLabel formats a profile for display: "Work (Profile 1)" when
+// the profile has a name of its own, otherwise the profile ID.
+func ProfileLabel(id, name string) string {
+	if name == "" || name == id {
+		return id
+	}
+	if id == "" {
+		return name
+	}
+	return name + " (" + id + ")"
+}
+
+// ID returns the name bookmarks from this source carry as their Source:
+// the instance name for configured instances, otherwise the adapter name.
+func (s SourceInfo) ID() string {
+	if s.Instance != "" {
+		return s.Instance
+	}
+	return s.Name
+}
+
 // NewCollection creates a new empty collection.
 func NewCollection() *Collection {
 	return &Collection{
@@ -0,0 +1,146 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Canonicalizer normalises URLs so that addresses of the same page
+// compare equal.
+//
+// Clean returns a tidied URL that still points at the same resource:
+// the scheme and host are lowercased, internationalised host names are
+// mapped as for an IDNA lookup and converted to punycode, default ports
+// and denied query parameters are removed, and the query can be sorted.
+// Key goes further for comparison only: it also ignores http versus
+// https, a leading "www.", the fragment and a trailing slash.
+type Canonicalizer struct {
+	// StripParams are query parameters to remove, matched
+	// case-insensitively; * matches any characters ("utm_*").
+	StripParams []string
+
+	// SortQuery orders the remaining query parameters by name.
+	SortQuery bool
+}
+
+// defaultPorts maps schemes to the port they use when none is given.
+var defaultPorts = map[string]string{"http": "80", "https": "443", "ftp": "21"}
+
+// Clean returns the cleaned form of a URL. URLs that cannot be parsed,
+// or have no host (mailto:, javascript:), are returned unchanged.
+func (c Canonicalizer) Clean(rawURL string) string {
+	u := c.parse(rawURL)
+	if u == nil {
+		return rawURL
+	}
+	return u.String()
+}
+
+// Key returns the comparison key of a URL, for deduplication.
+func (c Canonicalizer) Key(rawURL string) string {
+	u := c.parse(rawURL)
+	if u == nil {
+		return rawURL
+	}
+	if u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+	u.Host = strings.TrimPrefix(u.Host, "www.")
+	u.Fragment, u.RawFragment = "", ""
+	if len(u.Path) > 1 {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		u.RawPath = strings.TrimSuffix(u.RawPath, "/")
+	}
+	return u.String()
+}
+
+// parse parses and cleans a URL, returning nil if it has no host.
+func (c Canonicalizer) parse(rawURL string) *url.URL {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" || u.Opaque != "" {
+		return nil
+	}
+
+	// IDNA lookup mapping folds case and width and converts to punycode;
+	// hosts it rejects, such as IP literals, are only lowercased
+	host := strings.ToLower(u.Hostname())
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		host = ascii
+	}
+	host = strings.TrimSuffix(host, ".")
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]" // IPv6 literal
+	}
+	if port := u.Port(); port != "" && port != defaultPorts[u.Scheme] {
+		host += ":" + port
+	}
+	u.Host = host
+
+	if u.Path == "" && (u.Scheme == "http" || u.Scheme == "https") {
+		u.Path = "/"
+	}
+
+	u.RawQuery = c.cleanQuery(u.RawQuery)
+	u.ForceQuery = false
+	return u
+}
+
+// cleanQuery removes denied parameters and sorts the rest, keeping
+// their original encoding.
+func (c Canonicalizer) cleanQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	var params []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if !c.stripped(name) {
+			params = append(params, param)
+		}
+	}
+
+	if c.SortQuery {
+		sort.SliceStable(params, func(i, j int) bool {
+			a, _, _ := strings.Cut(params[i], "=")
+			b, _, _ := strings.Cut(params[j], "=")
+			return a < b
+		})
+	}
+	return strings.Join(params, "&")
+}
+
+// stripped reports whether a query parameter is denied.
+func (c Canonicalizer) stripped(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range c.StripParams {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
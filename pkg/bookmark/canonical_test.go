@@ -0,0 +1,59 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import "testing"
+
+func TestCanonicalizer(t *testing.T) {
+	c := Canonicalizer{StripParams: []string{"utm_*", "fbclid"}, SortQuery: true}
+
+	tests := []struct {
+		url, clean, key string
+	}{
+		{"HTTPS://Go.Dev", "https://go.dev/", "https://go.dev/"},
+		{"http://www.example.com:80/a/?b=2&a=1&utm_source=x#top",
+			"http://www.example.com/a/?a=1&b=2#top", "https://example.com/a?a=1&b=2"},
+		{"https://example.com:8443/?FBCLID=1", "https://example.com:8443/", "https://example.com:8443/"},
+		{"https://bücher.example/", "https://xn--bcher-kva.example/", "https://xn--bcher-kva.example/"},
+		{"https://BÜCHER.Example/", "https://xn--bcher-kva.example/", "https://xn--bcher-kva.example/"},
+		{"https://bu\u0308cher.example/", "https://xn--bcher-kva.example/", "https://xn--bcher-kva.example/"},
+		{"https://ｇｏ．ｄｅｖ/", "https://go.dev/", "https://go.dev/"},
+		{"https://例え.テスト/", "https://xn--r8jz45g.xn--zckzah/", "https://xn--r8jz45g.xn--zckzah/"},
+		{"https://straße.de/", "https://xn--strae-oqa.de/", "https://xn--strae-oqa.de/"},
+		{"https://[::1]:443/x", "https://[::1]/x", "https://[::1]/x"},
+		{"javascript:alert(1)", "javascript:alert(1)", "javascript:alert(1)"},
+	}
+
+	for _, tt := range tests {
+		if got := c.Clean(tt.url); got != tt.clean {
+			t.Errorf("Clean(%q) = %q, want %q", tt.url, got, tt.clean)
+		}
+		if got := c.Key(tt.url); got != tt.key {
+			t.Errorf("Key(%q) = %q, want %q", tt.url, got, tt.key)
+		}
+	}
+
+	bookmarks := []Bookmark{
+		{Title: "A", URL: "https://example.com/page?utm_medium=email"},
+		{Title: "B", URL: "http://www.example.com/page/"},
+		{Title: "C", URL: "https://example.com/other"},
+	}
+	if got := DeduplicateBy(bookmarks, c.Key); len(got) != 2 || got[1].Title != "C" {
+		t.Errorf("DeduplicateBy = %v", got)
+	}
+	if got := Deduplicate(bookmarks); len(got) != 3 {
+		t.Errorf("Deduplicate = %v", got)
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Reasons bookmarks are considered duplicates.
+const (
+	SameURL       = "same URL"
+	EquivalentURL = "equivalent URL"    // Same canonical URL
+	VariantURL    = "AMP or mobile URL" // Same page under an AMP or mobile address
+	SimilarTitle  = "similar title"     // Title token similarity above the threshold
+)
+
+// DuplicateOptions configures FindDuplicates.
+type DuplicateOptions struct {
+	// Canonicalizer compares URLs. Query parameters are always sorted.
+	Canonicalizer Canonicalizer
+
+	// TitleThreshold is the Jaccard similarity of title words at which
+	// bookmarks with different URLs are duplicates (0 = ignore titles).
+	TitleThreshold float64
+
+	// MinTitleWords skips titles with fewer words, which are too
+	// generic ("Home", "Login") to compare.
+	MinTitleWords int
+}
+
+// DuplicateCluster is a group of likely duplicates, in input order.
+type DuplicateCluster struct {
+	Bookmarks []Bookmark
+	Reasons   []string // Why they were grouped, see SameURL and friends
+}
+
+// FindDuplicates groups bookmarks that probably point at the same page:
+// those with the same URL, the same canonical URL, the same URL once
+// AMP and mobile variations are removed, or similar titles. Clusters are
+// ordered by size, largest first.
+func FindDuplicates(bookmarks []Bookmark, opts DuplicateOptions) []DuplicateCluster {
+	canon := opts.Canonicalizer
+	canon.SortQuery = true
+
+	parent := make([]int, len(bookmarks))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	reasons := make(map[int]map[string]bool)
+	union := func(a, b int, reason string) {
+		ra, rb := find(a), find(b)
+		if ra != rb {
+			parent[rb] = ra
+			for r := range reasons[rb] {
+				addReason(reasons, ra, r)
+			}
+			delete(reasons, rb)
+		}
+		addReason(reasons, ra, reason)
+	}
+
+	// Bookmarks sharing a URL key
+	firstByKey := make(map[string]int)
+	for i, b := range bookmarks {
+		key := variantKey(canon.Key(b.URL))
+		j, ok := firstByKey[key]
+		if !ok {
+			firstByKey[key] = i
+			continue
+		}
+		switch other := bookmarks[j].URL; {
+		case other == b.URL:
+			union(j, i, SameURL)
+		case canon.Key(other) == canon.Key(b.URL):
+			union(j, i, EquivalentURL)
+		default:
+			union(j, i, VariantURL)
+		}
+	}
+
+	// Bookmarks with similar titles, compared only with those sharing a word
+	if opts.TitleThreshold > 0 {
+		words := make([]map[string]bool, len(bookmarks))
+		byWord := make(map[string][]int)
+		for i, b := range bookmarks {
+			if w := titleWords(b.Title); len(w) >= max(opts.MinTitleWords, 1) {
+				words[i] = w
+			}
+			for w := range words[i] {
+				byWord[w] = append(byWord[w], i)
+			}
+		}
+		for i := range bookmarks {
+			compared := make(map[int]bool)
+			for w := range words[i] {
+				for _, j := range byWord[w] {
+					if j >= i || compared[j] || find(i) == find(j) {
+						continue
+					}
+					compared[j] = true
+					if jaccard(words[i], words[j]) >= opts.TitleThreshold {
+						union(j, i, SimilarTitle)
+					}
+				}
+			}
+		}
+	}
+
+	members := make(map[int][]Bookmark)
+	var roots []int
+	for i, b := range bookmarks {
+		r := find(i)
+		if _, ok := members[r]; !ok {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], b)
+	}
+
+	var clusters []DuplicateCluster
+	for _, r := range roots {
+		if len(members[r]) < 2 {
+			continue
+		}
+		c := DuplicateCluster{Bookmarks: members[r]}
+		for _, reason := range []string{SameURL, EquivalentURL, VariantURL, SimilarTitle} {
+			if reasons[r][reason] {
+				c.Reasons = append(c.Reasons, reason)
+			}
+		}
+		clusters = append(clusters, c)
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].Bookmarks) > len(clusters[j].Bookmarks)
+	})
+	return clusters
+}
+
+func addReason(reasons map[int]map[string]bool, i int, reason string) {
+	if reasons[i] == nil {
+		reasons[i] = make(map[string]bool)
+	}
+	reasons[i][reason] = true
+}
+
+// variantKey removes AMP and mobile variations from a canonical URL:
+// AMP cache addresses, m., mobile. and amp. hosts, a trailing /amp path
+// segment and amp query parameters.
+func variantKey(key string) string {
+	u, err := url.Parse(key)
+	if err != nil || u.Host == "" {
+		return key
+	}
+
+	// https://www.google.com/amp/s/example.com/a and
+	// https://example-com.cdn.ampproject.org/c/s/example.com/a
+	if rest, ok := strings.CutPrefix(u.Path, "/amp/s/"); ok && strings.HasPrefix(u.Host, "google.") {
+		return variantKey("https://" + rest + querySuffix(u))
+	}
+	if strings.HasSuffix(u.Host, ".cdn.ampproject.org") {
+		rest := strings.TrimPrefix(strings.TrimPrefix(u.Path, "/c"), "/v")
+		if rest, ok := strings.CutPrefix(rest, "/s/"); ok {
+			return variantKey("https://" + rest + querySuffix(u))
+		}
+	}
+
+	for _, prefix := range []string{"m.", "mobile.", "amp."} {
+		u.Host = strings.TrimPrefix(u.Host, prefix)
+	}
+	if trimmed := strings.TrimSuffix(u.Path, "/amp"); trimmed != u.Path {
+		u.Path, u.RawPath = trimmed, ""
+		if u.Path == "" {
+			u.Path = "/"
+		}
+	}
+
+	var params []string
+	for _, param := range strings.Split(u.RawQuery, "&") {
+		name, value, _ := strings.Cut(param, "=")
+		if param == "" || name == "amp" || (name == "outputType" && value == "amp") {
+			continue
+		}
+		params = append(params, param)
+	}
+	u.RawQuery = strings.Join(params, "&")
+	return u.String()
+}
+
+func querySuffix(u *url.URL) string {
+	if u.RawQuery == "" {
+		return ""
+	}
+	return "?" + u.RawQuery
+}
+
+// titleWords returns the lowercase words of a title.
+func titleWords(title string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[w] = true
+	}
+	return words
+}
+
+// jaccard returns the size of the intersection of two sets divided by
+// the size of their union.
+func jaccard(a, b map[string]bool) float64 {
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+	union := len(a) + len(b) - common
+	if union == 0 {
+		return 0
+	}
+	return float64(common) / float64(union)
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	bookmarks := []Bookmark{
+		{Title: "Rust release notes", URL: "https://example.com/news/rust?id=1&page=2"},
+		{Title: "Home", URL: "https://a.example.org/"},
+		{Title: "Rust release notes", URL: "https://m.example.com/news/rust/amp?page=2&id=1"},
+		{Title: "Home", URL: "https://b.example.org/"},
+		{Title: "Rust", URL: "https://www.google.com/amp/s/example.com/news/rust?id=1&page=2"},
+		{Title: "The Go Programming Language", URL: "https://go.dev/"},
+		{Title: "Go Programming Language", URL: "https://golang.org/"},
+		{Title: "The Go Programming Language", URL: "http://www.go.dev"},
+		{Title: "Zig", URL: "https://ziglang.org/"},
+		{Title: "Zig", URL: "https://ziglang.org/"},
+	}
+
+	clusters := FindDuplicates(bookmarks, DuplicateOptions{TitleThreshold: 0.7, MinTitleWords: 2})
+
+	var got []string
+	for _, c := range clusters {
+		got = append(got, fmt.Sprint(len(c.Bookmarks), c.Reasons))
+	}
+	want := []string{
+		"3 [AMP or mobile URL]",
+		"3 [equivalent URL similar title]",
+		"2 [same URL]",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("clusters = %v, want %v", got, want)
+	}
+
+	if c := FindDuplicates(bookmarks, DuplicateOptions{}); len(c) != 3 {
+		t.Errorf("without titles: %d clusters, want 3", len(c))
+	}
+}
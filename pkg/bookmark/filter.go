@@ -22,10 +22,16 @@ import (
 
 // FilterOptions configures bookmark filtering.
 type FilterOptions struct {
+	// Folders match as substrings of the "/"-joined folder path, so
+	// "Home" also matches "Homework". Rules match whole folder names.
 	IncludeFolders     []string // Only include bookmarks in these folders
 	ExcludeFolders     []string // Exclude bookmarks in these folders
 	ExcludeURLPatterns []string // Exclude URLs matching these regex patterns
 
+	// Rules are structured include and exclude rules, applied after the
+	// settings above.
+	Rules []Rule
+
 	// URL protocol filtering
 	ExcludeProtocols []string // Protocols to exclude (e.g., "data", "javascript")
 	WarnProtocols    []string // Protocols to warn about but include
@@ -35,17 +41,98 @@ type FilterOptions struct {
 
 // FilterResult contains the filtered bookmarks and any warnings generated.
 type FilterResult struct {
-	Bookmarks []Bookmark
-	Warnings  []string
-	Excluded  int // Count of excluded bookmarks
+	Bookmarks      []Bookmark
+	Warnings       []string        // WarningDetails, formatted with FilterWarning.String
+	WarningDetails []FilterWarning // The warnings with their bookmarks
+	Excluded       int             // Count of excluded bookmarks
+	Exclusions     []Exclusion     // Why each excluded bookmark was dropped
+	Errors         []string        // Filter options that could not be applied
+}
+
+// FilterWarning flags a bookmark that passed the filter but looks
+// suspicious.
+type FilterWarning struct {
+	Bookmark Bookmark
+	Message  string
+}
+
+// String formats the warning with the bookmark's title and URL.
+func (w FilterWarning) String() string {
+	return fmt.Sprintf("bookmark '%s' %s: %s", truncate(w.Bookmark.Title, 40), w.Message, truncate(w.Bookmark.URL, 60))
+}
+
+// Exclusion records a bookmark dropped by the filter.
+type Exclusion struct {
+	Bookmark Bookmark
+	Rule     string // ID of the rule that excluded it, see FilterOptions.RuleIDs
+	Reason   string // Human-readable explanation
+}
+
+// RuleIDs returns the IDs of the exclusion rules the options define, in
+// the order Filter evaluates them. An ID is the config key, followed by
+// the value for list settings: "exclude_protocols:data",
+// "max_url_length", "include_folders", "exclude_folders:Trash",
+// "exclude_url_patterns:^https://ads\.". Structured rules use their ID,
+// and bookmarks that match none of the include rules are excluded by
+// "include_rules".
+func (o FilterOptions) RuleIDs() []string {
+	var rules []string
+	for _, p := range o.ExcludeProtocols {
+		rules = append(rules, "exclude_protocols:"+strings.ToLower(p))
+	}
+	if o.MaxURLLength > 0 {
+		rules = append(rules, "max_url_length")
+	}
+	if len(o.IncludeFolders) > 0 {
+		rules = append(rules, "include_folders")
+	}
+	for _, f := range o.ExcludeFolders {
+		rules = append(rules, "exclude_folders:"+f)
+	}
+	for _, p := range o.ExcludeURLPatterns {
+		rules = append(rules, "exclude_url_patterns:"+p)
+	}
+
+	hasInclude := false
+	for i, r := range o.Rules {
+		if r.includes() {
+			hasInclude = true
+			continue
+		}
+		rules = append(rules, ruleID(i, r))
+	}
+	if hasInclude {
+		rules = append(rules, "include_rules")
+	}
+	return rules
 }
 
 // Filter applies filters to a collection of bookmarks.
 func Filter(bookmarks []Bookmark, opts FilterOptions) FilterResult {
+	var result FilterResult
+
 	var patterns []*regexp.Regexp
 	for _, p := range opts.ExcludeURLPatterns {
-		if re, err := regexp.Compile(p); err == nil {
-			patterns = append(patterns, re)
+		re, err := regexp.Compile(p)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("ignoring invalid URL pattern %q: %v", p, err))
+			continue
+		}
+		patterns = append(patterns, re)
+	}
+
+	var includeRules, excludeRules []compiledRule
+	for i, r := range opts.Rules {
+		id := ruleID(i, r)
+		c, err := compileRule(r, id)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("ignoring rule %s: %v", id, err))
+			continue
+		}
+		if r.includes() {
+			includeRules = append(includeRules, c)
+		} else {
+			excludeRules = append(excludeRules, c)
 		}
 	}
 
@@ -59,11 +146,9 @@ func Filter(bookmarks []Bookmark, opts FilterOptions) FilterResult {
 		warnProtos[strings.ToLower(p)] = true
 	}
 
-	var result FilterResult
 	for _, b := range bookmarks {
-		folderStr := strings.Join(b.FolderPath, "/")
 		excluded := false
-		var reason string
+		var rule, reason string
 
 		// Extract protocol from URL
 		proto := extractProtocol(b.URL)
@@ -71,16 +156,19 @@ func Filter(bookmarks []Bookmark, opts FilterOptions) FilterResult {
 		// Check protocol exclusion
 		if excludeProtos[proto] {
 			excluded = true
+			rule = "exclude_protocols:" + proto
 			reason = fmt.Sprintf("excluded protocol '%s'", proto)
 		}
 
 		// Check URL length exclusion
 		if !excluded && opts.MaxURLLength > 0 && len(b.URL) > opts.MaxURLLength {
 			excluded = true
+			rule = "max_url_length"
 			reason = fmt.Sprintf("URL length %d exceeds max %d", len(b.URL), opts.MaxURLLength)
 		}
 
 		// Check folder inclusion
+		folderStr := strings.Join(b.FolderPath, "/")
 		if !excluded && len(opts.IncludeFolders) > 0 {
 			matched := false
 			for _, inc := range opts.IncludeFolders {
@@ -91,6 +179,7 @@ func Filter(bookmarks []Bookmark, opts FilterOptions) FilterResult {
 			}
 			if !matched {
 				excluded = true
+				rule = "include_folders"
 				reason = "not in included folders"
 			}
 		}
@@ -100,6 +189,7 @@ func Filter(bookmarks []Bookmark, opts FilterOptions) FilterResult {
 			for _, exc := range opts.ExcludeFolders {
 				if strings.Contains(folderStr, exc) {
 					excluded = true
+					rule = "exclude_folders:" + exc
 					reason = fmt.Sprintf("in excluded folder '%s'", exc)
 					break
 				}
@@ -111,36 +201,68 @@ func Filter(bookmarks []Bookmark, opts FilterOptions) FilterResult {
 			for _, p := range patterns {
 				if p.MatchString(b.URL) {
 					excluded = true
-					reason = "matches excluded URL pattern"
+					rule = "exclude_url_patterns:" + p.String()
+					reason = fmt.Sprintf("matches excluded URL pattern '%s'", p)
 					break
 				}
 			}
 		}
 
+		// Check structured rules
+		if !excluded {
+			for _, r := range excludeRules {
+				if r.match(b) {
+					excluded = true
+					rule = r.id
+					reason = fmt.Sprintf("matches rule %s", r.id)
+					break
+				}
+			}
+		}
+		if !excluded && len(includeRules) > 0 {
+			matched := false
+			for _, r := range includeRules {
+				if r.match(b) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				excluded = true
+				rule = "include_rules"
+				reason = "matches no include rule"
+			}
+		}
+
 		if excluded {
 			result.Excluded++
-			// Generate warning for excluded bookmarks (optional, could be verbose mode only)
-			_ = reason // reason available for verbose logging if needed
+			result.Exclusions = append(result.Exclusions, Exclusion{Bookmark: b, Rule: rule, Reason: reason})
 			continue
 		}
 
 		// Generate warnings for included bookmarks
 		if warnProtos[proto] {
-			result.Warnings = append(result.Warnings,
-				fmt.Sprintf("bookmark '%s' uses protocol '%s': %s", truncate(b.Title, 40), proto, truncate(b.URL, 60)))
+			result.WarningDetails = append(result.WarningDetails, FilterWarning{Bookmark: b, Message: fmt.Sprintf("uses protocol '%s'", proto)})
 		}
 
 		if opts.WarnURLLength > 0 && len(b.URL) > opts.WarnURLLength {
-			result.Warnings = append(result.Warnings,
-				fmt.Sprintf("bookmark '%s' has long URL (%d chars): %s", truncate(b.Title, 40), len(b.URL), truncate(b.URL, 60)))
+			result.WarningDetails = append(result.WarningDetails, FilterWarning{Bookmark: b, Message: fmt.Sprintf("has long URL (%d chars)", len(b.URL))})
 		}
 
 		result.Bookmarks = append(result.Bookmarks, b)
 	}
 
+	for _, w := range result.WarningDetails {
+		result.Warnings = append(result.Warnings, w.String())
+	}
 	return result
 }
 
+// splitFolder splits a folder setting such as "Dev/Tools" into names.
+func splitFolder(s string) []string {
+	return strings.Split(strings.Trim(s, "/"), "/")
+}
+
 // extractProtocol extracts the protocol/scheme from a URL.
 func extractProtocol(url string) string {
 	idx := strings.Index(url, ":")
@@ -163,12 +285,19 @@ func truncate(s string, maxLen int) string {
 
 // Deduplicate removes duplicate bookmarks by URL.
 func Deduplicate(bookmarks []Bookmark) []Bookmark {
+	return DeduplicateBy(bookmarks, func(url string) string { return url })
+}
+
+// DeduplicateBy removes bookmarks whose URL has the same key as an
+// earlier bookmark's, such as Canonicalizer.Key.
+func DeduplicateBy(bookmarks []Bookmark, key func(url string) string) []Bookmark {
 	seen := make(map[string]bool)
 	var result []Bookmark
 
 	for _, b := range bookmarks {
-		if !seen[b.URL] {
-			seen[b.URL] = true
+		k := key(b.URL)
+		if !seen[k] {
+			seen[k] = true
 			result = append(result, b)
 		}
 	}
@@ -0,0 +1,142 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilter_Exclusions(t *testing.T) {
+	opts := FilterOptions{
+		ExcludeProtocols:   []string{"JavaScript", "data"},
+		ExcludeFolders:     []string{"Trash"},
+		ExcludeURLPatterns: []string{`^https://ads\.`, "("},
+		WarnProtocols:      []string{"file"},
+	}
+	bookmarks := []Bookmark{
+		{Title: "Go", URL: "https://go.dev/"},
+		{Title: "Bookmarklet", URL: "javascript:alert(1)"},
+		{Title: "Old", URL: "https://old.example.com/", FolderPath: []string{"Bar", "Trash"}},
+		{Title: "Ad", URL: "https://ads.example.com/"},
+		{Title: "Hosts", URL: "file:///etc/hosts"},
+	}
+
+	result := Filter(bookmarks, opts)
+
+	if len(result.Bookmarks) != 2 || result.Excluded != 3 {
+		t.Fatalf("kept %d, excluded %d", len(result.Bookmarks), result.Excluded)
+	}
+	wantRules := []string{"exclude_protocols:javascript", "exclude_folders:Trash", `exclude_url_patterns:^https://ads\.`}
+	for i, e := range result.Exclusions {
+		if e.Rule != wantRules[i] || e.Reason == "" {
+			t.Errorf("exclusion %d = %s (%s), want rule %s", i, e.Rule, e.Reason, wantRules[i])
+		}
+	}
+
+	rules := strings.Join(opts.RuleIDs(), " ")
+	for _, want := range wantRules {
+		if !strings.Contains(rules, want) {
+			t.Errorf("RuleIDs() = %s, missing %s", rules, want)
+		}
+	}
+
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], `"("`) {
+		t.Errorf("Errors = %v", result.Errors)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0] != "bookmark 'Hosts' uses protocol 'file': file:///etc/hosts" {
+		t.Errorf("Warnings = %v", result.Warnings)
+	}
+	if len(result.WarningDetails) != 1 || result.WarningDetails[0].Bookmark.Title != "Hosts" || result.WarningDetails[0].Message != "uses protocol 'file'" {
+		t.Errorf("WarningDetails = %+v", result.WarningDetails)
+	}
+}
+
+func TestFilter_Rules(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	bookmarks := []Bookmark{
+		{Title: "Wiki", URL: "https://wiki.intranet.example.com/", FolderPath: []string{"Bookmarks Bar", "Work", "Docs"}},
+		{Title: "Maths", URL: "https://maths.example.org/", FolderPath: []string{"Bookmarks Bar", "Homework"}},
+		{Title: "Old news", URL: "https://news.example.org/", DateAdded: day("2015-06-01")},
+		{Title: "Recipe", URL: "https://food.example.org/", Tags: []string{"Cooking"}, DateAdded: day("2024-03-01")},
+		{Title: "Draft: plan", URL: "https://notes.example.org/"},
+	}
+
+	tests := []struct {
+		name string
+		opts FilterOptions
+		kept []string
+	}{
+		{"legacy folders match substrings", FilterOptions{ExcludeFolders: []string{"Home"}},
+			[]string{"Wiki", "Old news", "Recipe", "Draft: plan"}},
+		{"rules match whole names", FilterOptions{Rules: []Rule{{Folder: "**/Home"}}},
+			[]string{"Wiki", "Maths", "Old news", "Recipe", "Draft: plan"}},
+		{"anchored glob", FilterOptions{Rules: []Rule{{Folder: "bookmarks bar/Work/**"}}},
+			[]string{"Maths", "Old news", "Recipe", "Draft: plan"}},
+		{"glob needs the root", FilterOptions{Rules: []Rule{{Folder: "Work/**"}}},
+			[]string{"Wiki", "Maths", "Old news", "Recipe", "Draft: plan"}},
+		{"subdomains", FilterOptions{Rules: []Rule{{Domains: []string{"example.com"}}}},
+			[]string{"Maths", "Old news", "Recipe", "Draft: plan"}},
+		{"dates", FilterOptions{Rules: []Rule{{AddedBefore: "2020"}}},
+			[]string{"Wiki", "Maths", "Recipe", "Draft: plan"}},
+		{"conditions are ANDed", FilterOptions{Rules: []Rule{{Tags: []string{"cooking"}, AddedAfter: "2025-01-01"}}},
+			[]string{"Wiki", "Maths", "Old news", "Recipe", "Draft: plan"}},
+		{"any", FilterOptions{Rules: []Rule{{Any: []Rule{{Title: "^Draft:"}, {Folder: "**/Homework"}}}}},
+			[]string{"Wiki", "Old news", "Recipe"}},
+		{"include", FilterOptions{Rules: []Rule{
+			{Action: "include", Domains: []string{"example.org"}},
+			{Action: "include", Folder: "Bookmarks Bar/*/Docs"},
+			{URL: "^https://notes\\."},
+		}}, []string{"Wiki", "Maths", "Old news", "Recipe"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Filter(bookmarks, tt.opts)
+			var kept []string
+			for _, b := range result.Bookmarks {
+				kept = append(kept, b.Title)
+			}
+			if strings.Join(kept, ",") != strings.Join(tt.kept, ",") {
+				t.Errorf("kept %v, want %v", kept, tt.kept)
+			}
+			if len(result.Errors) > 0 {
+				t.Errorf("Errors = %v", result.Errors)
+			}
+		})
+	}
+
+	opts := FilterOptions{Rules: []Rule{
+		{ID: "no-news", Domains: []string{"news.example.org"}},
+		{Action: "include", Tags: []string{"cooking"}},
+		{Title: "("},
+		{Action: "keep", Title: "x"},
+		{},
+	}}
+	result := Filter(bookmarks, opts)
+	if len(result.Bookmarks) != 1 || result.Exclusions[2].Rule != "no-news" || result.Exclusions[0].Rule != "include_rules" {
+		t.Errorf("Exclusions = %v", result.Exclusions)
+	}
+	if len(result.Errors) != 3 || !strings.Contains(result.Errors[0], "rules[2]") {
+		t.Errorf("Errors = %v", result.Errors)
+	}
+	if ids := strings.Join(opts.RuleIDs(), " "); ids != "no-news rules[2] rules[3] rules[4] include_rules" {
+		t.Errorf("RuleIDs() = %s", ids)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Root folder names used when roots are normalised.
+const (
+	RootToolbar = "Toolbar"
+	RootMenu    = "Menu"
+	RootOther   = "Other"
+	RootMobile  = "Mobile"
+)
+
+// rootNames maps the root folder names browsers use, lowercased, to
+// the normalised names.
+var rootNames = map[string]string{
+	"bookmarks bar":      RootToolbar, // Chrome, Brave, Chromium
+	"bookmarks toolbar":  RootToolbar, // Firefox
+	"toolbar":            RootToolbar,
+	"favorites bar":      RootToolbar, // Edge
+	"favourites bar":     RootToolbar,
+	"bookmarksbar":       RootToolbar, // Safari
+	"bookmarks menu":     RootMenu,
+	"menu":               RootMenu,
+	"bookmarksmenu":      RootMenu,
+	"other bookmarks":    RootOther,
+	"other favorites":    RootOther,
+	"other favourites":   RootOther,
+	"other":              RootOther,
+	"unfiled":            RootOther,
+	"unsorted bookmarks": RootOther,
+	"mobile bookmarks":   RootMobile,
+	"mobile favorites":   RootMobile,
+	"mobile":             RootMobile,
+}
+
+// FolderRename replaces the leading folders of a path.
+//
+// From is a glob anchored at the root, matched case-insensitively like
+// Rule.Folder, against the shortest run of leading folders it fits. A
+// leading "**/" matches the folders at any depth and keeps those above
+// them, so {From: "**/Dev Stuff", To: "Dev"} renames Dev Stuff wherever
+// it is. To may name several folders ("Work/Archive"), or none to
+// remove the matched folders.
+type FolderRename struct {
+	From string
+	To   string
+}
+
+// FolderOptions configures a FolderMapper.
+type FolderOptions struct {
+	// NormalizeRoots renames the root folders of each browser to
+	// Toolbar, Menu, Other and Mobile.
+	NormalizeRoots bool
+
+	// Rename rules are applied in order, after roots are normalised.
+	Rename []FolderRename
+
+	// MaxDepth moves bookmarks in folders deeper than this many levels
+	// up into their ancestor at that level (0 = no limit).
+	MaxDepth int
+}
+
+// FolderMapper rewrites folder paths according to FolderOptions.
+type FolderMapper struct {
+	opts  FolderOptions
+	rules []folderRule
+}
+
+type folderRule struct {
+	anywhere bool     // From started with **/
+	from     []string // Lowercase glob segments
+	to       []string
+}
+
+// NewFolderMapper compiles folder options. Rename rules with invalid
+// globs are skipped, with a message for each.
+func NewFolderMapper(opts FolderOptions) (*FolderMapper, []string) {
+	m := &FolderMapper{opts: opts}
+	var errs []string
+	for i, r := range opts.Rename {
+		rule := folderRule{from: splitFolder(strings.ToLower(r.From))}
+		if len(rule.from) > 1 && rule.from[0] == "**" {
+			rule.anywhere = true
+			rule.from = rule.from[1:]
+		}
+		if to := strings.Trim(r.To, "/"); to != "" {
+			rule.to = strings.Split(to, "/")
+		}
+
+		valid := r.From != ""
+		for _, seg := range rule.from {
+			if _, err := path.Match(seg, ""); err != nil {
+				valid = false
+			}
+		}
+		if !valid {
+			errs = append(errs, fmt.Sprintf("ignoring folder rename %d: invalid from %q", i, r.From))
+			continue
+		}
+		m.rules = append(m.rules, rule)
+	}
+	return m, errs
+}
+
+// Map returns the bookmarks with their folder paths rewritten. The
+// input is left unchanged.
+func (m *FolderMapper) Map(bookmarks []Bookmark) []Bookmark {
+	result := make([]Bookmark, len(bookmarks))
+	for i, b := range bookmarks {
+		b.FolderPath = m.MapPath(b.FolderPath)
+		result[i] = b
+	}
+	return result
+}
+
+// MapPath rewrites a single folder path.
+func (m *FolderMapper) MapPath(folders []string) []string {
+	folders = append([]string(nil), folders...)
+	if m.opts.NormalizeRoots && len(folders) > 0 {
+		if root, ok := rootNames[strings.ToLower(folders[0])]; ok {
+			folders[0] = root
+		}
+	}
+	for _, r := range m.rules {
+		folders = r.apply(folders)
+	}
+	if m.opts.MaxDepth > 0 && len(folders) > m.opts.MaxDepth {
+		folders = folders[:m.opts.MaxDepth]
+	}
+	return folders
+}
+
+// apply replaces the first run of folders the rule matches.
+func (r folderRule) apply(folders []string) []string {
+	starts := 1
+	if r.anywhere {
+		starts = len(folders) + 1
+	}
+	for start := 0; start < starts; start++ {
+		for end := start; end <= len(folders); end++ {
+			if !matchGlob(r.from, folders[start:end]) {
+				continue
+			}
+			result := append([]string(nil), folders[:start]...)
+			result = append(result, r.to...)
+			return append(result, folders[end:]...)
+		}
+	}
+	return folders
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFolderMapper(t *testing.T) {
+	m, errs := NewFolderMapper(FolderOptions{
+		NormalizeRoots: true,
+		Rename: []FolderRename{
+			{From: "Other/Imported*", To: ""},
+			{From: "**/Dev Stuff", To: "Dev"},
+			{From: "Menu", To: "Toolbar/From Menu"},
+			{From: "[", To: "x"},
+		},
+		MaxDepth: 3,
+	})
+	if len(errs) != 1 || !strings.Contains(errs[0], `"["`) {
+		t.Errorf("errors = %v", errs)
+	}
+
+	tests := []struct{ in, want string }{
+		{"Bookmarks bar/Work", "Toolbar/Work"},
+		{"Favorites bar/Work", "Toolbar/Work"},
+		{"Bookmarks Toolbar/Work", "Toolbar/Work"},
+		{"Other bookmarks/Imported From Chrome/News", "News"},
+		{"Other bookmarks/Reading/Dev Stuff/Go", "Other/Reading/Dev"},
+		{"Bookmarks Menu/a/b/c", "Toolbar/From Menu/a"},
+		{"Work/Bookmarks bar", "Work/Bookmarks bar"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		var in []string
+		if tt.in != "" {
+			in = strings.Split(tt.in, "/")
+		}
+		if got := strings.Join(m.MapPath(in), "/"); got != tt.want {
+			t.Errorf("MapPath(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	bookmarks := []Bookmark{{Title: "Go", FolderPath: []string{"Bookmarks bar", "Dev Stuff"}}}
+	if got := m.Map(bookmarks); strings.Join(got[0].FolderPath, "/") != "Toolbar/Dev" || bookmarks[0].FolderPath[0] != "Bookmarks bar" {
+		t.Errorf("Map = %v, input %v", got, bookmarks)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import "strings"
+
+// Merge strategies for duplicate bookmarks.
+const (
+	// MergeFirstWins keeps the first bookmark and drops the others.
+	MergeFirstWins = "first-wins"
+
+	// MergeAll keeps the first bookmark, carrying the occurrences, tags
+	// and earliest date of all of them.
+	MergeAll = "merge"
+
+	// MergePreferSource merges like MergeAll, but keeps the title,
+	// folder and source of the bookmark from the most preferred source.
+	MergePreferSource = "prefer-source"
+)
+
+// MergeStrategies lists the valid merge strategies.
+var MergeStrategies = []string{MergeFirstWins, MergeAll, MergePreferSource}
+
+// Occurrence is a place a bookmark was found.
+type Occurrence struct {
+	Source     string
+	Profile    string
+	FolderPath []string
+}
+
+// String formats the occurrence as "source/profile/folder/path".
+func (o Occurrence) String() string {
+	var parts []string
+	for _, s := range append([]string{o.Source, o.Profile}, o.FolderPath...) {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// AlsoIn returns the other places a merged bookmark was found.
+func (b Bookmark) AlsoIn() []Occurrence {
+	if len(b.Occurrences) < 2 {
+		return nil
+	}
+	return b.Occurrences[1:]
+}
+
+// MergeOptions configures Merge.
+type MergeOptions struct {
+	Strategy      string                  // One of MergeStrategies (default: first-wins)
+	PreferSources []string                // Sources in order of preference, for prefer-source
+	Key           func(url string) string // Duplicate key, such as Canonicalizer.Key (default: the URL)
+}
+
+// Merge combines bookmarks whose URLs share a key, keeping the position
+// of the first. With first-wins it behaves like DeduplicateBy. Otherwise
+// each merged bookmark lists every occurrence in Occurrences (its own
+// first), the union of the tags and the earliest DateAdded.
+func Merge(bookmarks []Bookmark, opts MergeOptions) []Bookmark {
+	key := opts.Key
+	if key == nil {
+		key = func(url string) string { return url }
+	}
+	if opts.Strategy == "" || opts.Strategy == MergeFirstWins {
+		return DeduplicateBy(bookmarks, key)
+	}
+
+	var order []string
+	groups := make(map[string][]Bookmark)
+	for _, b := range bookmarks {
+		k := key(b.URL)
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], b)
+	}
+
+	result := make([]Bookmark, 0, len(order))
+	for _, k := range order {
+		group := groups[k]
+		if len(group) == 1 {
+			result = append(result, group[0])
+			continue
+		}
+		if opts.Strategy == MergePreferSource {
+			group = preferSource(group, opts.PreferSources)
+		}
+		result = append(result, mergeGroup(group))
+	}
+	return result
+}
+
+// preferSource moves the bookmark from the most preferred source to the
+// front of the group.
+func preferSource(group []Bookmark, sources []string) []Bookmark {
+	rank := func(b Bookmark) int {
+		for i, s := range sources {
+			if strings.EqualFold(s, b.Source) {
+				return i
+			}
+		}
+		return len(sources)
+	}
+
+	best := 0
+	for i, b := range group {
+		if rank(b) < rank(group[best]) {
+			best = i
+		}
+	}
+	if best == 0 {
+		return group
+	}
+	reordered := append([]Bookmark{group[best]}, group[:best]...)
+	return append(reordered, group[best+1:]...)
+}
+
+// mergeGroup merges duplicates into the first of them.
+func mergeGroup(group []Bookmark) Bookmark {
+	merged := group[0]
+	merged.Tags = nil
+	merged.Occurrences = nil
+
+	seenTags := make(map[string]bool)
+	for _, b := range group {
+		merged.Occurrences = append(merged.Occurrences, Occurrence{
+			Source: b.Source, Profile: b.Profile, FolderPath: b.FolderPath,
+		})
+		for _, t := range b.Tags {
+			if !seenTags[strings.ToLower(t)] {
+				seenTags[strings.ToLower(t)] = true
+				merged.Tags = append(merged.Tags, t)
+			}
+		}
+		if !b.DateAdded.IsZero() && (merged.DateAdded.IsZero() || b.DateAdded.Before(merged.DateAdded)) {
+			merged.DateAdded = b.DateAdded
+		}
+		if merged.Title == "" {
+			merged.Title = b.Title
+		}
+	}
+	return merged
+}
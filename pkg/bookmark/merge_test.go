@@ -0,0 +1,59 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMerge(t *testing.T) {
+	early := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	bookmarks := []Bookmark{
+		{Title: "Go", URL: "https://go.dev/", FolderPath: []string{"Bar", "Dev"}, Source: "chrome", Profile: "Default", DateAdded: late},
+		{Title: "Rust", URL: "https://rust-lang.org/", Source: "chrome", Profile: "Default"},
+		{Title: "The Go site", URL: "https://go.dev/", FolderPath: []string{"Work"}, Source: "firefox", Tags: []string{"go", "lang"}, DateAdded: early},
+		{Title: "go.dev", URL: "https://go.dev/", Source: "safari", Tags: []string{"Go", "docs"}},
+	}
+
+	if got := Merge(bookmarks, MergeOptions{}); len(got) != 2 || got[0].Occurrences != nil {
+		t.Errorf("first-wins = %v", got)
+	}
+
+	got := Merge(bookmarks, MergeOptions{Strategy: MergeAll})
+	if len(got) != 2 || got[1].Title != "Rust" || got[1].Occurrences != nil {
+		t.Fatalf("merge = %v", got)
+	}
+	g := got[0]
+	if g.Title != "Go" || g.Source != "chrome" || !g.DateAdded.Equal(early) {
+		t.Errorf("merged = %+v", g)
+	}
+	if fmt.Sprint(g.Tags) != "[go lang docs]" {
+		t.Errorf("tags = %v", g.Tags)
+	}
+	if fmt.Sprint(g.AlsoIn()) != "[firefox/Work safari]" || g.Occurrences[0].String() != "chrome/Default/Bar/Dev" {
+		t.Errorf("occurrences = %v", g.Occurrences)
+	}
+
+	got = Merge(bookmarks, MergeOptions{Strategy: MergePreferSource, PreferSources: []string{"firefox"}})
+	if g := got[0]; g.Title != "The Go site" || g.Source != "firefox" || fmt.Sprint(g.AlsoIn()) != "[chrome/Default/Bar/Dev safari]" {
+		t.Errorf("prefer-source = %+v", g)
+	}
+	if bookmarks[0].Tags != nil || bookmarks[2].Tags[0] != "go" {
+		t.Error("Merge modified its input")
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Rule is a structured filter rule. Every condition that is set must
+// match (AND); Any and All combine nested rules with OR and AND. A
+// bookmark matching an exclude rule is dropped. If there are include
+// rules, a bookmark must match at least one of them to be kept.
+//
+//	Rule{Folder: "Bookmarks Bar/Work/**", Any: []Rule{
+//	    {Domains: []string{"intranet.example.com"}},
+//	    {Tags: []string{"internal"}},
+//	}}
+type Rule struct {
+	ID     string // Name used in the filter audit (default: rules[<index>])
+	Action string // "exclude" (default) or "include"; ignored on nested rules
+
+	// Folder is a glob matched case-insensitively against the whole
+	// folder path, anchored at the root: * and ? match within a folder
+	// name, ** matches any number of folders. "**/Work" matches a Work
+	// folder anywhere.
+	Folder string
+
+	Domains     []string // The host is, or is a subdomain of, one of these
+	Tags        []string // The bookmark has one of these tags
+	Title       string   // Regular expression matched against the title
+	URL         string   // Regular expression matched against the URL
+	AddedBefore string   // Added before this date (YYYY, YYYY-MM or YYYY-MM-DD)
+	AddedAfter  string   // Added after this date, as above
+
+	Any []Rule // At least one nested rule matches
+	All []Rule // Every nested rule matches
+}
+
+// ruleID returns the audit ID of the i-th top-level rule.
+func ruleID(i int, r Rule) string {
+	if r.ID != "" {
+		return r.ID
+	}
+	return fmt.Sprintf("rules[%d]", i)
+}
+
+// includes reports whether the rule is an include rule.
+func (r Rule) includes() bool {
+	return strings.EqualFold(r.Action, "include")
+}
+
+// compiledRule is a Rule with its patterns and dates parsed.
+type compiledRule struct {
+	id       string
+	folder   []string
+	domains  []string
+	tags     []string
+	title    *regexp.Regexp
+	url      *regexp.Regexp
+	dates    []QueryTerm
+	any, all []compiledRule
+}
+
+func compileRule(r Rule, id string) (compiledRule, error) {
+	c := compiledRule{id: id, tags: r.Tags}
+	conditions := 0
+
+	switch strings.ToLower(r.Action) {
+	case "", "exclude", "include":
+	default:
+		return c, fmt.Errorf("unknown action %q (expected exclude or include)", r.Action)
+	}
+
+	if r.Folder != "" {
+		c.folder = splitFolder(strings.ToLower(r.Folder))
+		for _, seg := range c.folder {
+			if _, err := path.Match(seg, ""); err != nil {
+				return c, fmt.Errorf("invalid folder glob %q", r.Folder)
+			}
+		}
+		conditions++
+	}
+	for _, d := range r.Domains {
+		c.domains = append(c.domains, strings.TrimPrefix(strings.ToLower(d), "www."))
+	}
+	if len(r.Domains) > 0 {
+		conditions++
+	}
+	if len(r.Tags) > 0 {
+		conditions++
+	}
+
+	for _, re := range []struct {
+		pattern string
+		dst     **regexp.Regexp
+		name    string
+	}{{r.Title, &c.title, "title"}, {r.URL, &c.url, "URL"}} {
+		if re.pattern == "" {
+			continue
+		}
+		compiled, err := regexp.Compile(re.pattern)
+		if err != nil {
+			return c, fmt.Errorf("invalid %s pattern %q: %v", re.name, re.pattern, err)
+		}
+		*re.dst = compiled
+		conditions++
+	}
+
+	for _, d := range []struct{ op, value, key string }{
+		{"<", r.AddedBefore, "added_before"},
+		{">", r.AddedAfter, "added_after"},
+	} {
+		if d.value == "" {
+			continue
+		}
+		term := QueryTerm{Field: "added"}
+		if err := term.parseDate(d.op + d.value); err != nil {
+			return c, fmt.Errorf("invalid %s %q: %v", d.key, d.value, err)
+		}
+		c.dates = append(c.dates, term)
+		conditions++
+	}
+
+	for _, nested := range []struct {
+		rules []Rule
+		dst   *[]compiledRule
+	}{{r.Any, &c.any}, {r.All, &c.all}} {
+		for _, n := range nested.rules {
+			cn, err := compileRule(n, id)
+			if err != nil {
+				return c, err
+			}
+			*nested.dst = append(*nested.dst, cn)
+		}
+		if len(nested.rules) > 0 {
+			conditions++
+		}
+	}
+
+	if conditions == 0 {
+		return c, fmt.Errorf("rule has no conditions")
+	}
+	return c, nil
+}
+
+// match reports whether a bookmark satisfies every condition of the rule.
+func (r compiledRule) match(b Bookmark) bool {
+	if r.folder != nil && !matchGlob(r.folder, b.FolderPath) {
+		return false
+	}
+	if len(r.domains) > 0 {
+		host := hostOf(b.URL)
+		found := false
+		for _, d := range r.domains {
+			if host == d || strings.HasSuffix(host, "."+d) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(r.tags) > 0 {
+		found := false
+		for _, t := range r.tags {
+			if hasTag(b, t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.title != nil && !r.title.MatchString(b.Title) {
+		return false
+	}
+	if r.url != nil && !r.url.MatchString(b.URL) {
+		return false
+	}
+	for _, d := range r.dates {
+		if !d.Match(b) {
+			return false
+		}
+	}
+	for _, n := range r.all {
+		if !n.match(b) {
+			return false
+		}
+	}
+	if len(r.any) > 0 {
+		found := false
+		for _, n := range r.any {
+			if n.match(b) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// matchGlob matches folder path segments against lowercase glob
+// segments, where "**" matches zero or more segments.
+func matchGlob(pattern, folders []string) bool {
+	if len(pattern) == 0 {
+		return len(folders) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(folders); i++ {
+			if matchGlob(pattern[1:], folders[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(folders) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], strings.ToLower(folders[0])); !ok {
+		return false
+	}
+	return matchGlob(pattern[1:], folders[1:])
+}
@@ -0,0 +1,360 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Query is a parsed search query.
+//
+// The query language is a list of space-separated terms, all of which
+// must match:
+//
+//	go tutorial            free text, matched against title, URL, folders, tags
+//	                       and description
+//	"exact phrase"         free text containing spaces
+//	-exclude               negation; works on any term (e.g. -tag:old)
+//	tag:go                 bookmark has the tag
+//	folder:Dev/Tools       folder path contains these consecutive folders
+//	site:github.com        URL host is the domain or a subdomain of it
+//	title:go  url:/docs/   substring of title or URL
+//	source:chrome          input adapter name
+//	profile:Work           profile directory ("Profile 1") or the name
+//	                       the user gave it ("Work")
+//	added:>2025-01-01      date added; also >=, <, <= and a bare date.
+//	                       Dates may be 2006, 2006-01 or 2006-01-02.
+//
+// Field values may be quoted (folder:"Bookmarks Bar/Dev"). Matching is
+// case-insensitive. Words with an unknown field prefix (such as URLs)
+// are treated as free text.
+type Query struct {
+	Terms []QueryTerm
+}
+
+// QueryTerm is a single term of a query.
+type QueryTerm struct {
+	// Field is the field the term applies to, or empty for free text.
+	Field string
+
+	// Value is the text to match, lower-cased.
+	Value string
+
+	// Negate excludes bookmarks that match the term.
+	Negate bool
+
+	// Op is the comparison for added: terms (">", ">=", "<", "<=" or "=").
+	Op string
+
+	// from and to bound added: terms as the half-open range [from, to).
+	from, to time.Time
+}
+
+// queryFields are the recognised field prefixes.
+var queryFields = map[string]bool{
+	"tag":     true,
+	"folder":  true,
+	"site":    true,
+	"title":   true,
+	"url":     true,
+	"source":  true,
+	"profile": true,
+	"added":   true,
+}
+
+// ParseQuery parses a search query.
+func ParseQuery(s string) (Query, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return Query{}, err
+	}
+
+	var q Query
+	for _, tok := range tokens {
+		term := QueryTerm{}
+		text := tok
+
+		if strings.HasPrefix(text, "-") && len(text) > 1 {
+			term.Negate = true
+			text = text[1:]
+		}
+
+		if field, value, ok := strings.Cut(text, ":"); ok && queryFields[strings.ToLower(field)] {
+			term.Field = strings.ToLower(field)
+			text = value
+		}
+
+		text = strings.ReplaceAll(text, `"`, "")
+		if text == "" {
+			if term.Field != "" {
+				return Query{}, fmt.Errorf("missing value for %s:", term.Field)
+			}
+			continue
+		}
+		term.Value = strings.ToLower(text)
+
+		switch term.Field {
+		case "site":
+			term.Value = strings.TrimPrefix(term.Value, "www.")
+		case "added":
+			if err := term.parseDate(text); err != nil {
+				return Query{}, fmt.Errorf("invalid date in %q: %w", tok, err)
+			}
+		}
+
+		q.Terms = append(q.Terms, term)
+	}
+	return q, nil
+}
+
+// tokenize splits a query on whitespace, keeping quoted text together.
+func tokenize(s string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	inQuote := false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			cur.WriteRune(r)
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			if cur.Len() > 0 {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote in query")
+	}
+	if cur.Len() > 0 {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
+// parseDate parses the operator and date of an added: term.
+func (t *QueryTerm) parseDate(s string) error {
+	t.Op = "="
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if rest, ok := strings.CutPrefix(s, op); ok {
+			t.Op = op
+			s = rest
+			break
+		}
+	}
+
+	layouts := []struct {
+		layout string
+		next   func(time.Time) time.Time
+	}{
+		{"2006-01-02", func(d time.Time) time.Time { return d.AddDate(0, 0, 1) }},
+		{"2006-01", func(d time.Time) time.Time { return d.AddDate(0, 1, 0) }},
+		{"2006", func(d time.Time) time.Time { return d.AddDate(1, 0, 0) }},
+	}
+	for _, l := range layouts {
+		if d, err := time.ParseInLocation(l.layout, s, time.Local); err == nil {
+			t.from, t.to = d, l.next(d)
+			return nil
+		}
+	}
+	return fmt.Errorf("expected YYYY, YYYY-MM or YYYY-MM-DD")
+}
+
+// Match reports whether a bookmark satisfies every term of the query.
+func (q Query) Match(b Bookmark) bool {
+	for _, t := range q.Terms {
+		if t.Match(b) == t.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+// Match reports whether a bookmark matches the term, ignoring Negate.
+func (t QueryTerm) Match(b Bookmark) bool {
+	switch t.Field {
+	case "":
+		return containsFold(b.Title, t.Value) ||
+			containsFold(b.URL, t.Value) ||
+			containsFold(strings.Join(b.FolderPath, "/"), t.Value) ||
+			hasTag(b, t.Value) ||
+			containsFold(b.Description, t.Value)
+	case "tag":
+		return hasTag(b, t.Value)
+	case "folder":
+		return inFolder(b.FolderPath, strings.Split(strings.Trim(t.Value, "/"), "/"))
+	case "site":
+		host := hostOf(b.URL)
+		return host == t.Value || strings.HasSuffix(host, "."+t.Value)
+	case "title":
+		return containsFold(b.Title, t.Value)
+	case "url":
+		return containsFold(b.URL, t.Value)
+	case "source":
+		return strings.EqualFold(b.Source, t.Value)
+	case "profile":
+		return strings.EqualFold(b.Profile, t.Value) || strings.EqualFold(b.ProfileName, t.Value)
+	case "added":
+		if b.DateAdded.IsZero() {
+			return false
+		}
+		d := b.DateAdded
+		switch t.Op {
+		case ">":
+			return !d.Before(t.to)
+		case ">=":
+			return !d.Before(t.from)
+		case "<":
+			return d.Before(t.from)
+		case "<=":
+			return d.Before(t.to)
+		default:
+			return !d.Before(t.from) && d.Before(t.to)
+		}
+	}
+	return false
+}
+
+// Score ranks a matching bookmark: higher is more relevant. Only
+// positive free-text terms contribute; a title match outweighs a tag
+// match, which outweighs a match in the URL, folder path or description.
+func (q Query) Score(b Bookmark) float64 {
+	title := strings.ToLower(b.Title)
+	var score float64
+
+	for _, t := range q.Terms {
+		if t.Negate || t.Field != "" {
+			continue
+		}
+		switch {
+		case title == t.Value:
+			score += 10
+		case strings.HasPrefix(title, t.Value):
+			score += 6
+		case strings.Contains(title, t.Value):
+			score += 4
+		}
+		if hasTag(b, t.Value) {
+			score += 3
+		}
+		if strings.Contains(hostOf(b.URL), t.Value) {
+			score += 2
+		} else if containsFold(b.URL, t.Value) {
+			score++
+		}
+		if containsFold(strings.Join(b.FolderPath, "/"), t.Value) {
+			score++
+		}
+		if containsFold(b.Description, t.Value) {
+			score++
+		}
+	}
+	return score
+}
+
+// SearchOptions pages search results.
+type SearchOptions struct {
+	Limit  int // Maximum results to return (0 = no limit)
+	Offset int // Results to skip
+}
+
+// SearchResult is a bookmark matched by a search, with its score.
+type SearchResult struct {
+	Bookmark Bookmark
+	Score    float64
+}
+
+// Search returns the bookmarks matching q, most relevant first, along
+// with the total number of matches before paging. Bookmarks with equal
+// scores keep their input order.
+func Search(bookmarks []Bookmark, q Query, opts SearchOptions) ([]SearchResult, int) {
+	var results []SearchResult
+	for _, b := range bookmarks {
+		if q.Match(b) {
+			results = append(results, SearchResult{Bookmark: b, Score: q.Score(b)})
+		}
+	}
+	return Rank(results, opts)
+}
+
+// Rank sorts results by descending score, keeping the order of equal
+// scores, and applies paging. It returns the page and the total number
+// of results.
+func Rank(results []SearchResult, opts SearchOptions) ([]SearchResult, int) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	total := len(results)
+	if opts.Offset > 0 {
+		if opts.Offset >= len(results) {
+			return nil, total
+		}
+		results = results[opts.Offset:]
+	}
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, total
+}
+
+// containsFold reports whether s contains the lower-case substr,
+// ignoring case.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), substr)
+}
+
+func hasTag(b Bookmark, tag string) bool {
+	for _, t := range b.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// inFolder reports whether want appears as consecutive folders in path.
+func inFolder(path, want []string) bool {
+	for i := 0; i+len(want) <= len(path); i++ {
+		matched := true
+		for j, name := range want {
+			if !strings.EqualFold(path[i+j], name) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// hostOf returns the lower-case host of a URL, without a "www." prefix.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"testing"
+	"time"
+)
+
+var searchBookmarks = []Bookmark{
+	{
+		Title:      "Go",
+		URL:        "https://go.dev/",
+		FolderPath: []string{"Bookmarks Bar", "Dev", "Tools"},
+		DateAdded:  time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local),
+		Source:     "chrome",
+		Tags:       []string{"go"},
+	},
+	{
+		Title:      "Awesome Go",
+		URL:        "https://github.com/avelino/awesome-go",
+		FolderPath: []string{"Bookmarks Bar", "Dev"},
+		DateAdded:  time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local),
+		Source:     "firefox",
+	},
+	{
+		Title:       "Gist",
+		URL:         "https://gist.github.com/example",
+		FolderPath:  []string{"Other", "Archive"},
+		Source:      "chrome",
+		Profile:     "Profile 1",
+		ProfileName: "Work",
+		Tags:        []string{"archived"},
+	},
+	{
+		Title:      "Getting started with Go modules",
+		URL:        "https://www.example.com/go-modules",
+		FolderPath: []string{"Reading"},
+		DateAdded:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local),
+		Source:     "chrome",
+	},
+}
+
+func searchTitles(t *testing.T, query string, opts SearchOptions) []string {
+	t.Helper()
+	q, err := ParseQuery(query)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q): %v", query, err)
+	}
+	results, _ := Search(searchBookmarks, q, opts)
+	var titles []string
+	for _, r := range results {
+		titles = append(titles, r.Bookmark.Title)
+	}
+	return titles
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"go", []string{"Go", "Awesome Go", "Getting started with Go modules"}},
+		{`"go modules"`, []string{"Getting started with Go modules"}},
+		{"tag:go", []string{"Go"}},
+		{"folder:dev", []string{"Go", "Awesome Go"}},
+		{"folder:Dev/Tools", []string{"Go"}},
+		{`folder:"Bookmarks Bar/Dev"`, []string{"Go", "Awesome Go"}},
+		{"site:github.com", []string{"Awesome Go", "Gist"}},
+		{"site:example.com", []string{"Getting started with Go modules"}},
+		{"site:github.com -tag:archived", []string{"Awesome Go"}},
+		{"go -site:github.com", []string{"Go", "Getting started with Go modules"}},
+		{"source:firefox", []string{"Awesome Go"}},
+		{`profile:"Profile 1"`, []string{"Gist"}},
+		{"profile:work", []string{"Gist"}},
+		{"added:>2025-01-01", []string{"Go"}},
+		{"added:>=2025-01-01", []string{"Go", "Getting started with Go modules"}},
+		{"added:2024", []string{"Awesome Go"}},
+		{"added:<2025", []string{"Awesome Go"}},
+		{"https://go.dev", []string{"Go"}},
+		{"nothing-matches-this", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := searchTitles(t, tt.query, SearchOptions{})
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSearch_Paging(t *testing.T) {
+	q, _ := ParseQuery("go")
+
+	results, total := Search(searchBookmarks, q, SearchOptions{Limit: 1, Offset: 1})
+	if total != 3 {
+		t.Errorf("total = %d, want 3", total)
+	}
+	if len(results) != 1 || results[0].Bookmark.Title != "Awesome Go" {
+		t.Errorf("results = %v", results)
+	}
+
+	if results, _ := Search(searchBookmarks, q, SearchOptions{Offset: 10}); len(results) != 0 {
+		t.Errorf("offset past end returned %d results", len(results))
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	for _, query := range []string{`"unterminated`, "added:yesterday", "tag:"} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("ParseQuery(%q) succeeded, want error", query)
+		}
+	}
+}
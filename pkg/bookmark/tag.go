@@ -0,0 +1,101 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import "fmt"
+
+// TagRule adds tags to the bookmarks that match its conditions. The
+// conditions are those of a filter Rule; its Action is ignored.
+//
+//	TagRule{Rule: Rule{Domains: []string{"github.com"}}, Add: []string{"code"}}
+type TagRule struct {
+	Rule
+	Add []string // Tags to add
+}
+
+// BuiltinTagRules tag common developer sites.
+var BuiltinTagRules = []TagRule{
+	domainTags([]string{"github.com", "gitlab.com", "bitbucket.org", "codeberg.org", "sr.ht"}, "code"),
+	domainTags([]string{"pkg.go.dev"}, "go-docs"),
+	domainTags([]string{"go.dev", "golang.org"}, "go"),
+	domainTags([]string{"docs.python.org"}, "python-docs"),
+	domainTags([]string{"pypi.org"}, "python"),
+	domainTags([]string{"docs.rs", "doc.rust-lang.org"}, "rust-docs"),
+	domainTags([]string{"crates.io", "rust-lang.org"}, "rust"),
+	domainTags([]string{"npmjs.com"}, "javascript"),
+	domainTags([]string{"developer.mozilla.org"}, "web-docs"),
+	domainTags([]string{"stackoverflow.com", "stackexchange.com", "serverfault.com", "superuser.com"}, "q-and-a"),
+	domainTags([]string{"hub.docker.com", "docs.docker.com"}, "docker"),
+	domainTags([]string{"kubernetes.io"}, "kubernetes"),
+	domainTags([]string{"arxiv.org"}, "paper"),
+	domainTags([]string{"youtube.com", "youtu.be", "vimeo.com"}, "video"),
+}
+
+func domainTags(domains []string, tags ...string) TagRule {
+	return TagRule{Rule: Rule{Domains: domains}, Add: tags}
+}
+
+// Tagger adds tags to bookmarks according to tag rules.
+type Tagger struct {
+	rules []compiledRule
+	adds  [][]string
+}
+
+// NewTagger compiles tag rules. Rules that cannot be compiled are
+// skipped, with a message for each.
+func NewTagger(rules []TagRule) (*Tagger, []string) {
+	t := &Tagger{}
+	var errs []string
+	for i, r := range rules {
+		id := ruleID(i, r.Rule)
+		c, err := compileRule(r.Rule, id)
+		if err == nil && len(r.Add) == 0 {
+			err = fmt.Errorf("rule adds no tags")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("ignoring tag rule %s: %v", id, err))
+			continue
+		}
+		t.rules = append(t.rules, c)
+		t.adds = append(t.adds, r.Add)
+	}
+	return t, errs
+}
+
+// Tag adds the tags of every matching rule to the bookmarks, skipping
+// tags a bookmark already has. The input is left unchanged.
+func (t *Tagger) Tag(bookmarks []Bookmark) []Bookmark {
+	result := make([]Bookmark, len(bookmarks))
+	for i, b := range bookmarks {
+		copied := false
+		for j, r := range t.rules {
+			if !r.match(b) {
+				continue
+			}
+			for _, tag := range t.adds[j] {
+				if hasTag(b, tag) {
+					continue
+				}
+				if !copied {
+					b.Tags = append([]string(nil), b.Tags...)
+					copied = true
+				}
+				b.Tags = append(b.Tags, tag)
+			}
+		}
+		result[i] = b
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bookmark
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTagger(t *testing.T) {
+	rules := append([]TagRule{
+		{Rule: Rule{Folder: "**/Recipes"}, Add: []string{"cooking"}},
+		{Rule: Rule{URL: `/issues/\d+$`}, Add: []string{"issue", "Code"}},
+		{Rule: Rule{ID: "broken", Title: "("}, Add: []string{"x"}},
+		{Rule: Rule{Domains: []string{"example.com"}}},
+	}, BuiltinTagRules...)
+
+	tagger, errs := NewTagger(rules)
+	if len(errs) != 2 || !strings.Contains(errs[0], "broken") || !strings.Contains(errs[1], "rules[3]") {
+		t.Errorf("errors = %v", errs)
+	}
+
+	bookmarks := []Bookmark{
+		{Title: "Go issue", URL: "https://github.com/golang/go/issues/1", Tags: []string{"golang"}},
+		{Title: "Pasta", URL: "https://food.example.com/", FolderPath: []string{"Bar", "Recipes"}},
+		{Title: "time", URL: "https://pkg.go.dev/time"},
+		{Title: "Other", URL: "https://example.org/"},
+	}
+	tagged := tagger.Tag(bookmarks)
+
+	want := []string{"[golang issue Code]", "[cooking]", "[go-docs go]", "[]"}
+	for i, b := range tagged {
+		if fmt.Sprint(b.Tags) != want[i] {
+			t.Errorf("%s tags = %v, want %s", b.Title, b.Tags, want[i])
+		}
+	}
+	if len(bookmarks[0].Tags) != 1 {
+		t.Error("Tag modified its input")
+	}
+}
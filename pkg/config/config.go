@@ -16,8 +16,12 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -25,48 +29,95 @@ import (
 // Config represents the full configuration.
 type Config struct {
 	Inputs   InputsConfig   `yaml:"inputs"`
+	Sources  []SourceConfig `yaml:"sources"`
 	Outputs  OutputsConfig  `yaml:"outputs"`
 	Pipeline PipelineConfig `yaml:"pipeline"`
+	Index    IndexConfig    `yaml:"index"`
+	History  HistoryConfig  `yaml:"history"`
 }
 
-// InputsConfig configures input adapters.
-type InputsConfig struct {
-	Chrome   InputConfig `yaml:"chrome"`
-	Edge     InputConfig `yaml:"edge"`
-	Firefox  InputConfig `yaml:"firefox"`
-	Safari   InputConfig `yaml:"safari"`
-	Chromium InputConfig `yaml:"chromium"`
-	Brave    InputConfig `yaml:"brave"`
-}
+// InputsConfig configures input adapters, keyed by adapter name.
+type InputsConfig map[string]InputConfig
 
 // InputConfig configures a single input adapter.
 type InputConfig struct {
 	Enabled    bool   `yaml:"enabled"`
 	Profile    string `yaml:"profile"`
 	CustomPath string `yaml:"custom_path"`
+
+	// Options are passed to the adapter as input.Config.Options.
+	Options map[string]interface{} `yaml:"options"`
 }
 
-// OutputsConfig configures output adapters.
-type OutputsConfig struct {
-	Markdown OutputConfig `yaml:"markdown"`
-	JSON     OutputConfig `yaml:"json"`
-	YAML     OutputConfig `yaml:"yaml"`
+// SourceConfig configures a named instance of an input adapter. Each
+// source gets its own adapter, so several sources may use the same
+// adapter with different paths or options.
+type SourceConfig struct {
+	Name    string `yaml:"name"`    // Unique name, used as the bookmarks' source
+	Adapter string `yaml:"adapter"` // Input adapter, e.g. opml or chrome
+	Path    string `yaml:"path"`    // Passed to the adapter as its custom path
+	Profile string `yaml:"profile"` // Profile to read (default: all)
+
+	// Options are passed to the adapter as input.Config.Options.
+	Options map[string]interface{} `yaml:"options"`
 }
 
+// OutputsConfig configures output adapters, keyed by adapter name.
+type OutputsConfig map[string]OutputConfig
+
 // OutputConfig configures a single output adapter.
 type OutputConfig struct {
-	Enabled bool              `yaml:"enabled"`
-	Style   string            `yaml:"style"`
-	Options map[string]string `yaml:"options"`
+	Enabled bool   `yaml:"enabled"`
+	Style   string `yaml:"style"`
+
+	// Options are passed to the adapter as output.Config.Options.
+	Options map[string]interface{} `yaml:"options"`
+}
+
+// UnmarshalYAML merges each adapter's settings into any existing entry,
+// so a config file only needs to mention the fields it changes.
+func (c *InputsConfig) UnmarshalYAML(node *yaml.Node) error {
+	return mergeMap(node, (*map[string]InputConfig)(c))
+}
+
+// UnmarshalYAML merges each adapter's settings into any existing entry,
+// so a config file only needs to mention the fields it changes.
+func (c *OutputsConfig) UnmarshalYAML(node *yaml.Node) error {
+	return mergeMap(node, (*map[string]OutputConfig)(c))
+}
+
+func mergeMap[T any](node *yaml.Node, m *map[string]T) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping of adapter names", node.Line)
+	}
+	if *m == nil {
+		*m = make(map[string]T)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name := node.Content[i].Value
+		entry := (*m)[name]
+		if err := node.Content[i+1].Decode(&entry); err != nil {
+			return err
+		}
+		(*m)[name] = entry
+	}
+	return nil
 }
 
 // PipelineConfig configures the processing pipeline.
 type PipelineConfig struct {
+	Read      ReadConfig      `yaml:"read"`
 	Filter    FilterConfig    `yaml:"filter"`
 	Transform TransformConfig `yaml:"transform"`
 	Render    RenderConfig    `yaml:"render"`
 }
 
+// ReadConfig configures how inputs are read.
+type ReadConfig struct {
+	Workers int           `yaml:"workers"` // Inputs read concurrently (0 = all at once)
+	Timeout time.Duration `yaml:"timeout"` // Per-input read timeout, e.g. 30s (0 = none)
+}
+
 // FilterConfig configures bookmark filtering.
 type FilterConfig struct {
 	IncludeFolders     []string `yaml:"include_folders"`
@@ -78,12 +129,71 @@ type FilterConfig struct {
 	WarnProtocols    []string `yaml:"warn_protocols"`    // Protocols to warn about but include
 	MaxURLLength     int      `yaml:"max_url_length"`    // Exclude URLs longer than this (0 = no limit)
 	WarnURLLength    int      `yaml:"warn_url_length"`   // Warn on URLs longer than this (0 = no warning)
+
+	// Structured rules, applied after the settings above
+	Rules []FilterRule `yaml:"rules"`
+}
+
+// FilterRule is a structured filter rule; see bookmark.Rule.
+type FilterRule struct {
+	ID          string       `yaml:"id"`
+	Action      string       `yaml:"action"` // exclude (default) or include
+	Folder      string       `yaml:"folder"` // Glob over the folder path, e.g. "Bookmarks Bar/Work/**"
+	Domains     []string     `yaml:"domains"`
+	Tags        []string     `yaml:"tags"`
+	Title       string       `yaml:"title"` // Regular expression
+	URL         string       `yaml:"url"`   // Regular expression
+	AddedBefore string       `yaml:"added_before"`
+	AddedAfter  string       `yaml:"added_after"`
+	Any         []FilterRule `yaml:"any"`
+	All         []FilterRule `yaml:"all"`
 }
 
 // TransformConfig configures bookmark transformation.
 type TransformConfig struct {
-	Deduplicate bool `yaml:"deduplicate"`
-	Sort        bool `yaml:"sort"`
+	Deduplicate   bool               `yaml:"deduplicate"`
+	Merge         string             `yaml:"merge"`          // first-wins, merge or prefer-source
+	PreferSources []string           `yaml:"prefer_sources"` // Source order for prefer-source
+	Sort          bool               `yaml:"sort"`
+	Canonicalize  CanonicalizeConfig `yaml:"canonicalize"`
+	Tagging       TaggingConfig      `yaml:"tagging"`
+	Folders       FoldersConfig      `yaml:"folders"`
+}
+
+// FoldersConfig configures folder remapping.
+type FoldersConfig struct {
+	NormalizeRoots bool           `yaml:"normalize_roots"` // Rename browser root folders to Toolbar, Menu, Other, Mobile
+	Rename         []FolderRename `yaml:"rename"`          // Applied in order, after normalize_roots
+	MaxDepth       int            `yaml:"max_depth"`       // Flatten folders deeper than this (0 = no limit)
+}
+
+// FolderRename replaces the leading folders matching From with To; see
+// bookmark.FolderRename.
+type FolderRename struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// TaggingConfig configures rule-based tagging.
+type TaggingConfig struct {
+	Enabled bool      `yaml:"enabled"`
+	Builtin bool      `yaml:"builtin"` // Apply the built-in rules for developer sites
+	Rules   []TagRule `yaml:"rules"`
+}
+
+// TagRule adds tags to the bookmarks matching its conditions, which
+// are those of a FilterRule.
+type TagRule struct {
+	FilterRule `yaml:",inline"`
+	Add        []string `yaml:"add"`
+}
+
+// CanonicalizeConfig configures URL canonicalization.
+type CanonicalizeConfig struct {
+	Enabled     bool     `yaml:"enabled"`      // Deduplicate by canonical URL
+	RewriteURLs bool     `yaml:"rewrite_urls"` // Output cleaned URLs instead of the originals
+	StripParams []string `yaml:"strip_params"` // Query parameters to remove; * is a wildcard
+	SortQuery   bool     `yaml:"sort_query"`   // Sort query parameters by name
 }
 
 // RenderConfig configures rendering options.
@@ -95,19 +205,36 @@ type RenderConfig struct {
 	GroupBySource   bool `yaml:"group_by_source"`
 }
 
+// IndexConfig configures the on-disk search index.
+type IndexConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"` // Index directory (default: ~/.favs/index)
+}
+
+// HistoryConfig configures the snapshot history store.
+type HistoryConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	Path         string `yaml:"path"`          // Database file (default: ~/.favs/history.db)
+	MaxSnapshots int    `yaml:"max_snapshots"` // Snapshots kept per scope (0 = unlimited)
+}
+
 // Default returns a configuration with sensible defaults.
 func Default() Config {
 	return Config{
 		Inputs: InputsConfig{
-			Chrome:  InputConfig{Enabled: true},
-			Edge:    InputConfig{Enabled: true},
-			Firefox: InputConfig{Enabled: true},
-			Safari:  InputConfig{Enabled: true},
+			"chrome":  {Enabled: true},
+			"edge":    {Enabled: true},
+			"firefox": {Enabled: true},
+			"safari":  {Enabled: true},
 		},
 		Outputs: OutputsConfig{
-			Markdown: OutputConfig{Enabled: true, Style: "textual"},
+			"markdown": {Enabled: true, Style: "textual"},
 		},
 		Pipeline: PipelineConfig{
+			Read: ReadConfig{
+				Workers: 4,
+				Timeout: 30 * time.Second,
+			},
 			Filter: FilterConfig{
 				ExcludeFolders:   []string{"Trash"},
 				ExcludeProtocols: []string{"data", "javascript"},
@@ -116,6 +243,18 @@ func Default() Config {
 			},
 			Transform: TransformConfig{
 				Deduplicate: false,
+				Merge:       "first-wins",
+				Canonicalize: CanonicalizeConfig{
+					Enabled: true,
+					StripParams: []string{
+						"utm_*", "fbclid", "gclid", "dclid", "msclkid", "yclid",
+						"mc_cid", "mc_eid", "igshid", "_hsenc", "_hsmi",
+					},
+					SortQuery: true,
+				},
+				Tagging: TaggingConfig{
+					Builtin: true,
+				},
 			},
 			Render: RenderConfig{
 				IncludeMetadata: true,
@@ -125,6 +264,9 @@ func Default() Config {
 				GroupBySource:   true,
 			},
 		},
+		History: HistoryConfig{
+			MaxSnapshots: 100,
+		},
 	}
 }
 
@@ -144,15 +286,76 @@ func Load(path string) (Config, error) {
 		return cfg, err
 	}
 
+	if err := cfg.validateSources(); err != nil {
+		return cfg, err
+	}
+
+	switch cfg.Pipeline.Transform.Merge {
+	case "", "first-wins", "merge", "prefer-source":
+	default:
+		return cfg, fmt.Errorf("pipeline.transform.merge: unknown strategy %q (expected first-wins, merge or prefer-source)", cfg.Pipeline.Transform.Merge)
+	}
+
 	return cfg, nil
 }
 
+// ReservedSourceNames are the first path segments of the MCP resource
+// URIs that do not name a source (favs://all, favs://tag/go, ...), so
+// a source cannot be called any of them.
+var ReservedSourceNames = []string{"all", "markdown", "folder", "tag"}
+
+func (c *Config) validateSources() error {
+	seen := make(map[string]bool)
+	for i, src := range c.Sources {
+		switch {
+		case src.Name == "":
+			return fmt.Errorf("sources[%d]: missing name", i)
+		case src.Adapter == "":
+			return fmt.Errorf("source %s: missing adapter", src.Name)
+		case seen[src.Name]:
+			return fmt.Errorf("source %s: duplicate name", src.Name)
+		case slices.Contains(ReservedSourceNames, src.Name):
+			return fmt.Errorf("source %s: reserved name (%s are used by MCP resources)", src.Name, strings.Join(ReservedSourceNames, ", "))
+		}
+		seen[src.Name] = true
+	}
+	return nil
+}
+
 // DefaultPath returns the default config file path.
 func DefaultPath() string {
 	home, _ := os.UserHomeDir()
 	return filepath.Join(home, ".favs", "config.yaml")
 }
 
+// DefaultIndexPath returns the default search index directory.
+func DefaultIndexPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".favs", "index")
+}
+
+// IndexPath returns the configured search index directory.
+func (c *Config) IndexPath() string {
+	if c.Index.Path != "" {
+		return c.Index.Path
+	}
+	return DefaultIndexPath()
+}
+
+// DefaultHistoryPath returns the default snapshot database path.
+func DefaultHistoryPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".favs", "history.db")
+}
+
+// HistoryPath returns the configured snapshot database path.
+func (c *Config) HistoryPath() string {
+	if c.History.Path != "" {
+		return c.History.Path
+	}
+	return DefaultHistoryPath()
+}
+
 // LocalPath returns a local config file path if it exists.
 func LocalPath() string {
 	paths := []string{
@@ -173,35 +376,22 @@ func LocalPath() string {
 }
 
 // GetInputConfig returns the config for a specific input adapter.
+// Adapters without an entry are disabled.
 func (c *Config) GetInputConfig(name string) InputConfig {
-	switch name {
-	case "chrome":
-		return c.Inputs.Chrome
-	case "edge":
-		return c.Inputs.Edge
-	case "firefox":
-		return c.Inputs.Firefox
-	case "safari":
-		return c.Inputs.Safari
-	case "chromium":
-		return c.Inputs.Chromium
-	case "brave":
-		return c.Inputs.Brave
-	default:
-		return InputConfig{}
+	return c.Inputs[name]
+}
+
+// GetSource returns the named source instance, if configured.
+func (c *Config) GetSource(name string) (SourceConfig, bool) {
+	for _, src := range c.Sources {
+		if src.Name == name {
+			return src, true
+		}
 	}
+	return SourceConfig{}, false
 }
 
 // GetOutputConfig returns the config for a specific output adapter.
 func (c *Config) GetOutputConfig(name string) OutputConfig {
-	switch name {
-	case "markdown":
-		return c.Outputs.Markdown
-	case "json":
-		return c.Outputs.JSON
-	case "yaml":
-		return c.Outputs.YAML
-	default:
-		return OutputConfig{}
-	}
+	return c.Outputs[name]
 }
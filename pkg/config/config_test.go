@@ -0,0 +1,208 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadYAML(t *testing.T, data string) Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return cfg
+}
+
+func TestLoad_MergesAdapterDefaults(t *testing.T) {
+	// A file written for the old fixed-field layout
+	cfg := loadYAML(t, `
+inputs:
+  chrome:
+    profile: Work
+  brave:
+    enabled: true
+    custom_path: /opt/brave
+outputs:
+  markdown:
+    style: table
+`)
+
+	chrome := cfg.GetInputConfig("chrome")
+	if !chrome.Enabled || chrome.Profile != "Work" {
+		t.Errorf("chrome = %+v, want enabled by default with profile Work", chrome)
+	}
+	if brave := cfg.GetInputConfig("brave"); !brave.Enabled || brave.CustomPath != "/opt/brave" {
+		t.Errorf("brave = %+v", brave)
+	}
+	if !cfg.GetInputConfig("firefox").Enabled {
+		t.Error("firefox default lost")
+	}
+	if md := cfg.GetOutputConfig("markdown"); !md.Enabled || md.Style != "table" {
+		t.Errorf("markdown = %+v", md)
+	}
+}
+
+func TestLoad_AdapterOptions(t *testing.T) {
+	cfg := loadYAML(t, `
+inputs:
+  pinboard:
+    enabled: true
+    options:
+      api_token: user:secret
+      count: 100
+outputs:
+  csv:
+    options:
+      delimiter: ";"
+`)
+
+	pinboard := cfg.GetInputConfig("pinboard")
+	if !pinboard.Enabled {
+		t.Error("pinboard not enabled")
+	}
+	if pinboard.Options["api_token"] != "user:secret" || pinboard.Options["count"] != 100 {
+		t.Errorf("pinboard options = %v", pinboard.Options)
+	}
+	if cfg.GetOutputConfig("csv").Options["delimiter"] != ";" {
+		t.Errorf("csv options = %v", cfg.GetOutputConfig("csv").Options)
+	}
+	if cfg.GetInputConfig("unknown").Enabled {
+		t.Error("unconfigured adapter is enabled")
+	}
+}
+
+func TestLoad_Sources(t *testing.T) {
+	cfg := loadYAML(t, `
+sources:
+  - name: work-export
+    adapter: opml
+    path: /tmp/work.html
+  - name: old-chrome
+    adapter: chrome
+    path: /backup/Chrome
+    profile: Default
+`)
+
+	src, ok := cfg.GetSource("old-chrome")
+	if !ok || src.Adapter != "chrome" || src.Path != "/backup/Chrome" || src.Profile != "Default" {
+		t.Errorf("old-chrome = %+v, %v", src, ok)
+	}
+	if _, ok := cfg.GetSource("home-export"); ok {
+		t.Error("found an unconfigured source")
+	}
+
+	for _, bad := range []string{
+		"sources:\n  - adapter: opml\n",
+		"sources:\n  - name: x\n",
+		"sources:\n  - {name: x, adapter: opml}\n  - {name: x, adapter: opml}\n",
+		"sources:\n  - {name: tag, adapter: opml}\n",
+	} {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("Load accepted %q", bad)
+		}
+	}
+}
+
+func TestLoad_FilterRules(t *testing.T) {
+	cfg := loadYAML(t, `
+pipeline:
+  filter:
+    rules:
+      - id: old-work
+        folder: "Bookmarks Bar/Work/**"
+        added_before: 2020-01-01
+        any:
+          - domains: [intranet.example.com]
+          - tags: [internal]
+`)
+
+	rules := cfg.Pipeline.Filter.Rules
+	if len(rules) != 1 {
+		t.Fatalf("rules = %+v", rules)
+	}
+	r := rules[0]
+	if r.ID != "old-work" || r.Folder != "Bookmarks Bar/Work/**" || r.AddedBefore != "2020-01-01" {
+		t.Errorf("rule = %+v", r)
+	}
+	if len(r.Any) != 2 || r.Any[0].Domains[0] != "intranet.example.com" || r.Any[1].Tags[0] != "internal" {
+		t.Errorf("any = %+v", r.Any)
+	}
+	if len(cfg.Pipeline.Filter.ExcludeProtocols) == 0 {
+		t.Error("filter defaults lost")
+	}
+}
+
+func TestLoad_MergeStrategy(t *testing.T) {
+	if cfg := loadYAML(t, "pipeline:\n  transform:\n    merge: prefer-source\n"); cfg.Pipeline.Transform.Merge != "prefer-source" {
+		t.Errorf("merge = %q", cfg.Pipeline.Transform.Merge)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("pipeline:\n  transform:\n    merge: newest\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load accepted an unknown merge strategy")
+	}
+}
+
+func TestLoad_Tagging(t *testing.T) {
+	cfg := loadYAML(t, `
+pipeline:
+  transform:
+    tagging:
+      enabled: true
+      rules:
+        - folder: "**/Recipes"
+          add: [cooking]
+`)
+
+	tagging := cfg.Pipeline.Transform.Tagging
+	if !tagging.Enabled || !tagging.Builtin {
+		t.Errorf("tagging = %+v, want enabled with built-in rules", tagging)
+	}
+	if len(tagging.Rules) != 1 || tagging.Rules[0].Folder != "**/Recipes" || tagging.Rules[0].Add[0] != "cooking" {
+		t.Errorf("rules = %+v", tagging.Rules)
+	}
+}
+
+func TestLoad_Folders(t *testing.T) {
+	cfg := loadYAML(t, `
+pipeline:
+  transform:
+    folders:
+      normalize_roots: true
+      rename:
+        - {from: "**/Dev Stuff", to: Dev}
+      max_depth: 2
+`)
+
+	f := cfg.Pipeline.Transform.Folders
+	if !f.NormalizeRoots || f.MaxDepth != 2 || len(f.Rename) != 1 || f.Rename[0].To != "Dev" {
+		t.Errorf("folders = %+v", f)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diag collects diagnostics raised while bookmarks are read,
+// filtered and transformed.
+//
+// The pipeline records failed reads and filter problems in a
+// Diagnostics value. Adapters that skip part of their input (a profile
+// that cannot be parsed, say) report it through the context they were
+// given:
+//
+//	if err != nil {
+//	    diag.Report(ctx, diag.Diagnostic{
+//	        Severity: diag.Warning,
+//	        Profile:  profile,
+//	        Message:  fmt.Sprintf("skipping profile: %v", err),
+//	    })
+//	    continue
+//	}
+package diag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+)
+
+// Severity classifies a diagnostic.
+type Severity string
+
+const (
+	// Error means input was lost: a source or profile could not be
+	// read, or a setting could not be applied.
+	Error Severity = "error"
+
+	// Warning flags something suspicious that was kept.
+	Warning Severity = "warning"
+
+	// Info records a deliberate decision, such as a filtered bookmark.
+	Info Severity = "info"
+)
+
+// Severities lists the severities from most to least severe.
+var Severities = []Severity{Error, Warning, Info}
+
+// rank orders severities; lower is more severe.
+func (s Severity) rank() int {
+	for i, sev := range Severities {
+		if sev == s {
+			return i
+		}
+	}
+	return len(Severities)
+}
+
+// AtLeast reports whether s is as severe as min.
+func (s Severity) AtLeast(min Severity) bool {
+	return s.rank() <= min.rank()
+}
+
+// Diagnostic is a single problem or notice.
+type Diagnostic struct {
+	Severity Severity
+	Source   string             // Input or source name, if any
+	Profile  string             // Profile within the source, if any
+	Bookmark *bookmark.Bookmark // Bookmark concerned, if any
+	Message  string
+}
+
+// String formats the diagnostic as "source/profile: message (title <url>)".
+func (d Diagnostic) String() string {
+	var sb strings.Builder
+	if d.Source != "" {
+		sb.WriteString(d.Source)
+		if d.Profile != "" {
+			sb.WriteString("/" + d.Profile)
+		}
+		sb.WriteString(": ")
+	}
+	sb.WriteString(d.Message)
+	if b := d.Bookmark; b != nil {
+		if b.Title != "" {
+			fmt.Fprintf(&sb, " (%s <%s>)", b.Title, b.URL)
+		} else {
+			fmt.Fprintf(&sb, " (<%s>)", b.URL)
+		}
+	}
+	return sb.String()
+}
+
+type jsonBookmark struct {
+	Title  string   `json:"title,omitempty"`
+	URL    string   `json:"url"`
+	Folder []string `json:"folder,omitempty"`
+}
+
+type jsonDiagnostic struct {
+	Severity Severity      `json:"severity"`
+	Source   string        `json:"source,omitempty"`
+	Profile  string        `json:"profile,omitempty"`
+	Bookmark *jsonBookmark `json:"bookmark,omitempty"`
+	Message  string        `json:"message"`
+}
+
+// MarshalJSON encodes the diagnostic with lowercase keys, and only the
+// identifying fields of its bookmark.
+func (d Diagnostic) MarshalJSON() ([]byte, error) {
+	v := jsonDiagnostic{Severity: d.Severity, Source: d.Source, Profile: d.Profile, Message: d.Message}
+	if b := d.Bookmark; b != nil {
+		v.Bookmark = &jsonBookmark{Title: b.Title, URL: b.URL, Folder: b.FolderPath}
+	}
+	return json.Marshal(v)
+}
+
+// Diagnostics collects diagnostics. It is safe for concurrent use; the
+// zero value is ready to use.
+type Diagnostics struct {
+	mu    sync.Mutex
+	items []Diagnostic
+}
+
+// Add records a diagnostic.
+func (d *Diagnostics) Add(diag Diagnostic) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.items = append(d.items, diag)
+}
+
+// Errorf records an error for a source.
+func (d *Diagnostics) Errorf(source, format string, args ...interface{}) {
+	d.Add(Diagnostic{Severity: Error, Source: source, Message: fmt.Sprintf(format, args...)})
+}
+
+// All returns the diagnostics in the order they were recorded.
+func (d *Diagnostics) All() []Diagnostic {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Diagnostic(nil), d.items...)
+}
+
+// AtLeast returns the diagnostics at least as severe as min.
+func (d *Diagnostics) AtLeast(min Severity) []Diagnostic {
+	var result []Diagnostic
+	for _, diag := range d.All() {
+		if diag.Severity.AtLeast(min) {
+			result = append(result, diag)
+		}
+	}
+	return result
+}
+
+// Count returns the number of diagnostics of each severity.
+func (d *Diagnostics) Count() map[Severity]int {
+	counts := make(map[Severity]int)
+	for _, diag := range d.All() {
+		counts[diag.Severity]++
+	}
+	return counts
+}
+
+// Summary describes the counts, e.g. "1 error, 2 warnings, 5 info".
+// It is empty if there are no diagnostics.
+func (d *Diagnostics) Summary() string {
+	counts := d.Count()
+	var parts []string
+	for _, sev := range Severities {
+		n := counts[sev]
+		if n == 0 {
+			continue
+		}
+		switch {
+		case sev == Info:
+			parts = append(parts, fmt.Sprintf("%d info", n))
+		case n == 1:
+			parts = append(parts, fmt.Sprintf("%d %s", n, sev))
+		default:
+			parts = append(parts, fmt.Sprintf("%d %ss", n, sev))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+type contextKey struct{}
+
+type scope struct {
+	diags  *Diagnostics
+	source string
+}
+
+// WithDiagnostics returns a context whose Report calls record into d.
+func WithDiagnostics(ctx context.Context, d *Diagnostics) context.Context {
+	return context.WithValue(ctx, contextKey{}, scope{diags: d})
+}
+
+// WithSource returns a context whose reports are attributed to source
+// unless they name one themselves.
+func WithSource(ctx context.Context, source string) context.Context {
+	s, ok := ctx.Value(contextKey{}).(scope)
+	if !ok {
+		return ctx
+	}
+	s.source = source
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// Report records a diagnostic in the context's Diagnostics, if it has
+// one. Adapters use it for problems they recover from.
+func Report(ctx context.Context, diag Diagnostic) {
+	s, ok := ctx.Value(contextKey{}).(scope)
+	if !ok {
+		return
+	}
+	if diag.Source == "" {
+		diag.Source = s.source
+	}
+	s.diags.Add(diag)
+}
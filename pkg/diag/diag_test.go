@@ -0,0 +1,69 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+)
+
+func TestReport(t *testing.T) {
+	// Without Diagnostics in the context, reports are dropped
+	Report(context.Background(), Diagnostic{Severity: Error, Message: "lost"})
+
+	var d Diagnostics
+	ctx := WithSource(WithDiagnostics(context.Background(), &d), "chrome")
+	Report(ctx, Diagnostic{Severity: Error, Profile: "Work", Message: "skipping profile: bad JSON"})
+	Report(ctx, Diagnostic{Severity: Info, Source: "firefox", Message: "note"})
+	d.Add(Diagnostic{
+		Severity: Warning,
+		Bookmark: &bookmark.Bookmark{Title: "Local", URL: "file:///etc/hosts"},
+		Message:  "uses protocol 'file'",
+	})
+	d.Add(Diagnostic{Severity: Warning, Message: "again"})
+
+	all := d.All()
+	if len(all) != 4 {
+		t.Fatalf("got %d diagnostics, want 4", len(all))
+	}
+	if got := all[0].String(); got != "chrome/Work: skipping profile: bad JSON" {
+		t.Errorf("String = %q", got)
+	}
+	if all[1].Source != "firefox" {
+		t.Errorf("explicit source overridden: %q", all[1].Source)
+	}
+	if got := all[2].String(); got != "uses protocol 'file' (Local <file:///etc/hosts>)" {
+		t.Errorf("String = %q", got)
+	}
+
+	if got := d.Summary(); got != "1 error, 2 warnings, 1 info" {
+		t.Errorf("Summary = %q", got)
+	}
+	if got := len(d.AtLeast(Warning)); got != 3 {
+		t.Errorf("AtLeast(Warning) = %d diagnostics, want 3", got)
+	}
+
+	data, err := json.Marshal(all[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"severity":"warning","bookmark":{"title":"Local","url":"file:///etc/hosts"},"message":"uses protocol 'file'"}`
+	if string(data) != want {
+		t.Errorf("JSON = %s\nwant %s", data, want)
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+)
+
+// Change is a bookmark present in both collections that was moved or
+// retitled.
+type Change struct {
+	Old bookmark.Bookmark
+	New bookmark.Bookmark
+}
+
+// Diff lists the differences between two collections.
+type Diff struct {
+	From Snapshot
+	To   Snapshot
+
+	Added    []bookmark.Bookmark
+	Removed  []bookmark.Bookmark
+	Moved    []Change
+	Retitled []Change
+}
+
+// Empty reports whether the collections were identical.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Moved) == 0 && len(d.Retitled) == 0
+}
+
+// identity is what makes two bookmarks "the same" across snapshots.
+type identity struct {
+	source, profile, url string
+}
+
+func identityOf(b bookmark.Bookmark) identity {
+	return identity{b.Source, b.Profile, b.URL}
+}
+
+// Compare reports how to changed relative to from. Bookmarks are matched
+// by source, profile and URL; a matched bookmark whose folder changed is
+// moved, one whose title changed is retitled (it may be both). When a URL
+// occurs several times, unchanged copies are paired first.
+func Compare(from, to *bookmark.Collection) Diff {
+	var d Diff
+
+	old := make(map[identity][]bookmark.Bookmark)
+	for _, b := range from.Bookmarks {
+		id := identityOf(b)
+		old[id] = append(old[id], b)
+	}
+
+	// Pair identical bookmarks first so duplicates don't show as changes
+	var unmatched []bookmark.Bookmark
+	for _, b := range to.Bookmarks {
+		id := identityOf(b)
+		if i := indexOf(old[id], b); i >= 0 {
+			old[id] = append(old[id][:i], old[id][i+1:]...)
+			continue
+		}
+		unmatched = append(unmatched, b)
+	}
+
+	for _, b := range unmatched {
+		id := identityOf(b)
+		if len(old[id]) == 0 {
+			d.Added = append(d.Added, b)
+			continue
+		}
+		prev := old[id][0]
+		old[id] = old[id][1:]
+
+		if !sameFolder(prev.FolderPath, b.FolderPath) {
+			d.Moved = append(d.Moved, Change{Old: prev, New: b})
+		}
+		if prev.Title != b.Title {
+			d.Retitled = append(d.Retitled, Change{Old: prev, New: b})
+		}
+	}
+
+	// Whatever is left in from was removed, reported in from's order
+	for _, b := range from.Bookmarks {
+		id := identityOf(b)
+		if i := indexOf(old[id], b); i >= 0 {
+			old[id] = append(old[id][:i], old[id][i+1:]...)
+			d.Removed = append(d.Removed, b)
+		}
+	}
+
+	return d
+}
+
+func indexOf(list []bookmark.Bookmark, b bookmark.Bookmark) int {
+	for i, c := range list {
+		if c.Title == b.Title && sameFolder(c.FolderPath, b.FolderPath) {
+			return i
+		}
+	}
+	return -1
+}
+
+func sameFolder(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Formats lists the formats supported by Render.
+var Formats = []string{"text", "json", "markdown"}
+
+// Render formats a diff as text, json or markdown.
+func Render(d Diff, format string) ([]byte, error) {
+	switch format {
+	case "text":
+		return renderText(d), nil
+	case "json":
+		data, err := json.MarshalIndent(jsonDiffOf(d), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "markdown":
+		return renderMarkdown(d), nil
+	default:
+		return nil, fmt.Errorf("unknown diff format: %s (available: %v)", format, Formats)
+	}
+}
+
+const timeLayout = "2006-01-02 15:04"
+
+// The JSON form of a diff, with the keys of the json output adapter.
+type jsonDiff struct {
+	From     jsonSnapshot   `json:"from"`
+	To       jsonSnapshot   `json:"to"`
+	Added    []jsonBookmark `json:"added"`
+	Removed  []jsonBookmark `json:"removed"`
+	Moved    []jsonChange   `json:"moved"`
+	Retitled []jsonChange   `json:"retitled"`
+}
+
+type jsonSnapshot struct {
+	ID        int64        `json:"id"`
+	CreatedAt time.Time    `json:"created_at"`
+	Scope     string       `json:"scope"`
+	Count     int          `json:"count"`
+	Sources   []jsonSource `json:"sources"`
+}
+
+type jsonSource struct {
+	Name        string `json:"name"`
+	Instance    string `json:"instance,omitempty"`
+	Profile     string `json:"profile,omitempty"`
+	ProfileName string `json:"profile_name,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Count       int    `json:"count"`
+}
+
+type jsonBookmark struct {
+	Title       string   `json:"title"`
+	URL         string   `json:"url"`
+	Folder      []string `json:"folder,omitempty"`
+	Source      string   `json:"source,omitempty"`
+	Profile     string   `json:"profile,omitempty"`
+	ProfileName string   `json:"profile_name,omitempty"`
+}
+
+type jsonChange struct {
+	Old jsonBookmark `json:"old"`
+	New jsonBookmark `json:"new"`
+}
+
+func jsonDiffOf(d Diff) jsonDiff {
+	return jsonDiff{
+		From:     jsonSnapshotOf(d.From),
+		To:       jsonSnapshotOf(d.To),
+		Added:    jsonBookmarks(d.Added),
+		Removed:  jsonBookmarks(d.Removed),
+		Moved:    jsonChanges(d.Moved),
+		Retitled: jsonChanges(d.Retitled),
+	}
+}
+
+func jsonSnapshotOf(s Snapshot) jsonSnapshot {
+	js := jsonSnapshot{ID: s.ID, CreatedAt: s.CreatedAt, Scope: s.Scope, Count: s.Count, Sources: []jsonSource{}}
+	for _, src := range s.Sources {
+		js.Sources = append(js.Sources, jsonSource{
+			Name: src.Name, Instance: src.Instance, Profile: src.Profile,
+			ProfileName: src.ProfileName, Path: src.Path, Count: src.Count,
+		})
+	}
+	return js
+}
+
+func jsonBookmarkOf(b bookmark.Bookmark) jsonBookmark {
+	return jsonBookmark{
+		Title: b.Title, URL: b.URL, Folder: b.FolderPath,
+		Source: b.Source, Profile: b.Profile, ProfileName: b.ProfileName,
+	}
+}
+
+func jsonBookmarks(bookmarks []bookmark.Bookmark) []jsonBookmark {
+	out := make([]jsonBookmark, 0, len(bookmarks))
+	for _, b := range bookmarks {
+		out = append(out, jsonBookmarkOf(b))
+	}
+	return out
+}
+
+func jsonChanges(changes []Change) []jsonChange {
+	out := make([]jsonChange, 0, len(changes))
+	for _, c := range changes {
+		out = append(out, jsonChange{Old: jsonBookmarkOf(c.Old), New: jsonBookmarkOf(c.New)})
+	}
+	return out
+}
+
+func renderText(d Diff) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Snapshot %d (%s) -> %d (%s)\n", d.From.ID, d.From.CreatedAt.Format(timeLayout), d.To.ID, d.To.CreatedAt.Format(timeLayout))
+	fmt.Fprintf(&buf, "%d added, %d removed, %d moved, %d retitled\n",
+		len(d.Added), len(d.Removed), len(d.Moved), len(d.Retitled))
+
+	for _, b := range d.Added {
+		fmt.Fprintf(&buf, "\n+ %s\n  %s\n  in %s\n", displayTitle(b), b.URL, folderOf(b))
+	}
+	for _, b := range d.Removed {
+		fmt.Fprintf(&buf, "\n- %s\n  %s\n  in %s\n", displayTitle(b), b.URL, folderOf(b))
+	}
+	for _, c := range d.Moved {
+		fmt.Fprintf(&buf, "\n> %s\n  %s\n  %s -> %s\n", displayTitle(c.New), c.New.URL, folderOf(c.Old), folderOf(c.New))
+	}
+	for _, c := range d.Retitled {
+		fmt.Fprintf(&buf, "\n~ %s -> %s\n  %s\n", displayTitle(c.Old), displayTitle(c.New), c.New.URL)
+	}
+	return buf.Bytes()
+}
+
+func renderMarkdown(d Diff) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Bookmark changes\n\n")
+	fmt.Fprintf(&buf, "Snapshot %d (%s) to %d (%s)\n", d.From.ID, d.From.CreatedAt.Format(timeLayout), d.To.ID, d.To.CreatedAt.Format(timeLayout))
+
+	section := func(title string, n int) {
+		fmt.Fprintf(&buf, "\n## %s (%d)\n\n", title, n)
+	}
+	if len(d.Added) > 0 {
+		section("Added", len(d.Added))
+		for _, b := range d.Added {
+			fmt.Fprintf(&buf, "- [%s](%s) in %s\n", displayTitle(b), b.URL, folderOf(b))
+		}
+	}
+	if len(d.Removed) > 0 {
+		section("Removed", len(d.Removed))
+		for _, b := range d.Removed {
+			fmt.Fprintf(&buf, "- [%s](%s) from %s\n", displayTitle(b), b.URL, folderOf(b))
+		}
+	}
+	if len(d.Moved) > 0 {
+		section("Moved", len(d.Moved))
+		for _, c := range d.Moved {
+			fmt.Fprintf(&buf, "- [%s](%s): %s → %s\n", displayTitle(c.New), c.New.URL, folderOf(c.Old), folderOf(c.New))
+		}
+	}
+	if len(d.Retitled) > 0 {
+		section("Retitled", len(d.Retitled))
+		for _, c := range d.Retitled {
+			fmt.Fprintf(&buf, "- %s → [%s](%s)\n", displayTitle(c.Old), displayTitle(c.New), c.New.URL)
+		}
+	}
+	if d.Empty() {
+		fmt.Fprintf(&buf, "\nNo changes.\n")
+	}
+	return buf.Bytes()
+}
+
+func displayTitle(b bookmark.Bookmark) string {
+	if b.Title == "" {
+		return b.URL
+	}
+	return b.Title
+}
+
+func folderOf(b bookmark.Bookmark) string {
+	if len(b.FolderPath) == 0 {
+		return "/"
+	}
+	return strings.Join(b.FolderPath, "/")
+}
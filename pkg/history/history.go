@@ -0,0 +1,283 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package history stores snapshots of synced bookmark collections and
+// compares them.
+//
+// Each sync records its collection in a SQLite database with a
+// timestamp and a scope (which inputs and profiles were read), so later
+// runs can report what was added, removed, moved or retitled:
+//
+//	store, err := history.Open(cfg.HistoryPath())
+//	if err != nil {
+//	    return err
+//	}
+//	defer store.Close()
+//	snap, err := store.Save(collection, "all", time.Now())
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ErrNotFound is returned when no snapshot matches a lookup.
+var ErrNotFound = errors.New("snapshot not found")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS snapshots (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	created_at INTEGER NOT NULL,
+	scope      TEXT NOT NULL,
+	sources    TEXT NOT NULL,
+	count      INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS bookmarks (
+	snapshot_id INTEGER NOT NULL REFERENCES snapshots(id) ON DELETE CASCADE,
+	position    INTEGER NOT NULL,
+	url         TEXT NOT NULL,
+	title       TEXT NOT NULL,
+	folder      TEXT NOT NULL,
+	date_added  INTEGER NOT NULL,
+	source      TEXT NOT NULL,
+	profile     TEXT NOT NULL,
+	tags        TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS bookmarks_snapshot ON bookmarks(snapshot_id, position);
+`
+
+// Snapshot describes a recorded sync.
+type Snapshot struct {
+	ID        int64                 `json:"id"`
+	CreatedAt time.Time             `json:"created_at"`
+	Scope     string                `json:"scope"`
+	Count     int                   `json:"count"`
+	Sources   []bookmark.SourceInfo `json:"sources"`
+}
+
+// Store is a SQLite-backed snapshot store.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (creating if needed) the snapshot database at path.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating history directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("opening history: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initialising history: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save records a collection as a new snapshot.
+func (s *Store) Save(c *bookmark.Collection, scope string, at time.Time) (Snapshot, error) {
+	sources, err := json.Marshal(c.Sources)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("encoding sources: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("saving snapshot: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO snapshots (created_at, scope, sources, count) VALUES (?, ?, ?, ?)`,
+		at.UnixNano(), scope, string(sources), len(c.Bookmarks))
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("saving snapshot: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("saving snapshot: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO bookmarks
+		(snapshot_id, position, url, title, folder, date_added, source, profile, tags)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("saving snapshot: %w", err)
+	}
+	defer stmt.Close()
+
+	for i, b := range c.Bookmarks {
+		folder, _ := json.Marshal(b.FolderPath)
+		tags, _ := json.Marshal(b.Tags)
+		var added int64
+		if !b.DateAdded.IsZero() {
+			added = b.DateAdded.UnixNano()
+		}
+		if _, err := stmt.Exec(id, i, b.URL, b.Title, string(folder), added, b.Source, b.Profile, string(tags)); err != nil {
+			return Snapshot{}, fmt.Errorf("saving snapshot: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Snapshot{}, fmt.Errorf("saving snapshot: %w", err)
+	}
+
+	return Snapshot{
+		ID:        id,
+		CreatedAt: time.Unix(0, at.UnixNano()),
+		Scope:     scope,
+		Count:     len(c.Bookmarks),
+		Sources:   c.Sources,
+	}, nil
+}
+
+// List returns all snapshots, oldest first.
+func (s *Store) List() ([]Snapshot, error) {
+	return s.query(`SELECT id, created_at, scope, sources, count FROM snapshots ORDER BY id`)
+}
+
+// Get returns the snapshot with the given ID.
+func (s *Store) Get(id int64) (Snapshot, error) {
+	return s.one(`SELECT id, created_at, scope, sources, count FROM snapshots WHERE id = ?`, id)
+}
+
+// Latest returns the most recent snapshot of a scope.
+func (s *Store) Latest(scope string) (Snapshot, error) {
+	return s.one(`SELECT id, created_at, scope, sources, count FROM snapshots
+		WHERE scope = ? ORDER BY id DESC LIMIT 1`, scope)
+}
+
+// Previous returns the snapshot of the same scope taken before snap.
+func (s *Store) Previous(snap Snapshot) (Snapshot, error) {
+	return s.one(`SELECT id, created_at, scope, sources, count FROM snapshots
+		WHERE scope = ? AND id < ? ORDER BY id DESC LIMIT 1`, snap.Scope, snap.ID)
+}
+
+// Before returns the most recent snapshot of a scope taken at or before
+// t, or the oldest snapshot of the scope if all are newer.
+func (s *Store) Before(scope string, t time.Time) (Snapshot, error) {
+	snap, err := s.one(`SELECT id, created_at, scope, sources, count FROM snapshots
+		WHERE scope = ? AND created_at <= ? ORDER BY created_at DESC, id DESC LIMIT 1`, scope, t.UnixNano())
+	if errors.Is(err, ErrNotFound) {
+		return s.one(`SELECT id, created_at, scope, sources, count FROM snapshots
+			WHERE scope = ? ORDER BY id LIMIT 1`, scope)
+	}
+	return snap, err
+}
+
+// Load returns the bookmarks recorded in a snapshot.
+func (s *Store) Load(id int64) (*bookmark.Collection, error) {
+	snap, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`SELECT url, title, folder, date_added, source, profile, tags
+		FROM bookmarks WHERE snapshot_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, fmt.Errorf("loading snapshot %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	c := &bookmark.Collection{Sources: snap.Sources}
+	for rows.Next() {
+		var b bookmark.Bookmark
+		var folder, tags string
+		var added int64
+		if err := rows.Scan(&b.URL, &b.Title, &folder, &added, &b.Source, &b.Profile, &tags); err != nil {
+			return nil, fmt.Errorf("loading snapshot %d: %w", id, err)
+		}
+		_ = json.Unmarshal([]byte(folder), &b.FolderPath)
+		_ = json.Unmarshal([]byte(tags), &b.Tags)
+		if added != 0 {
+			b.DateAdded = time.Unix(0, added)
+		}
+		c.Bookmarks = append(c.Bookmarks, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading snapshot %d: %w", id, err)
+	}
+	return c, nil
+}
+
+// Prune deletes the oldest snapshots of a scope, keeping the newest keep.
+func (s *Store) Prune(scope string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	_, err := s.db.Exec(`DELETE FROM snapshots WHERE scope = ? AND id NOT IN
+		(SELECT id FROM snapshots WHERE scope = ? ORDER BY id DESC LIMIT ?)`, scope, scope, keep)
+	if err != nil {
+		return fmt.Errorf("pruning history: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) one(query string, args ...interface{}) (Snapshot, error) {
+	snaps, err := s.query(query, args...)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if len(snaps) == 0 {
+		return Snapshot{}, ErrNotFound
+	}
+	return snaps[0], nil
+}
+
+func (s *Store) query(query string, args ...interface{}) ([]Snapshot, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+	defer rows.Close()
+
+	var snaps []Snapshot
+	for rows.Next() {
+		var snap Snapshot
+		var created int64
+		var sources string
+		if err := rows.Scan(&snap.ID, &created, &snap.Scope, &sources, &snap.Count); err != nil {
+			return nil, fmt.Errorf("reading history: %w", err)
+		}
+		snap.CreatedAt = time.Unix(0, created)
+		_ = json.Unmarshal([]byte(sources), &snap.Sources)
+		snaps = append(snaps, snap)
+	}
+	return snaps, rows.Err()
+}
+
+// Scope describes which inputs a sync read: "all" in all-inputs mode,
+// otherwise "<input>/<profile>" of the source that was read (the
+// instance name for named sources).
+func Scope(all bool, sources []bookmark.SourceInfo) string {
+	if all || len(sources) == 0 {
+		return "all"
+	}
+	return sources[0].ID() + "/" + sources[0].Profile
+}
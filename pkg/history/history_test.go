@@ -0,0 +1,145 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+)
+
+func collectionOf(bookmarks ...bookmark.Bookmark) *bookmark.Collection {
+	c := bookmark.NewCollection()
+	c.Add(bookmarks, bookmark.SourceInfo{Name: "chrome", Profile: "Default"})
+	return c
+}
+
+func bm(title, url string, folder ...string) bookmark.Bookmark {
+	return bookmark.Bookmark{Title: title, URL: url, FolderPath: folder, Source: "chrome", Profile: "Default"}
+}
+
+func TestStore(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	week := 7 * 24 * time.Hour
+	now := time.Now()
+	added := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	first := collectionOf(bookmark.Bookmark{
+		Title: "Go", URL: "https://go.dev/", FolderPath: []string{"Dev"},
+		DateAdded: added, Source: "chrome", Profile: "Default", Tags: []string{"golang"},
+	})
+	old, err := store.Save(first, "all", now.Add(-2*week))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mid, _ := store.Save(first, "all", now.Add(-week/2))
+	latest, _ := store.Save(collectionOf(), "all", now)
+	if _, err := store.Save(first, "chrome/Default", now); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Load(old.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Count() != 1 || len(loaded.Sources) != 1 {
+		t.Fatalf("loaded %d bookmarks, %d sources", loaded.Count(), len(loaded.Sources))
+	}
+	b := loaded.Bookmarks[0]
+	if b.Title != "Go" || b.FolderPath[0] != "Dev" || b.Tags[0] != "golang" || !b.DateAdded.Equal(added) {
+		t.Errorf("loaded bookmark = %+v", b)
+	}
+
+	if got, _ := store.Latest("all"); got.ID != latest.ID {
+		t.Errorf("Latest = %d, want %d", got.ID, latest.ID)
+	}
+	if got, _ := store.Previous(latest); got.ID != mid.ID {
+		t.Errorf("Previous = %d, want %d", got.ID, mid.ID)
+	}
+	if got, _ := store.Before("all", now.Add(-week)); got.ID != old.ID {
+		t.Errorf("Before(1w) = %d, want %d", got.ID, old.ID)
+	}
+	if got, _ := store.Before("all", now.Add(-4*week)); got.ID != old.ID {
+		t.Errorf("Before(4w) = %d, want oldest %d", got.ID, old.ID)
+	}
+
+	if err := store.Prune("all", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(old.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("pruned snapshot still present: %v", err)
+	}
+	snaps, _ := store.List()
+	if len(snaps) != 2 {
+		t.Errorf("List after prune = %d snapshots, want 2", len(snaps))
+	}
+}
+
+func TestCompare(t *testing.T) {
+	from := collectionOf(
+		bm("Go", "https://go.dev/", "Dev"),
+		bm("Rust", "https://rust-lang.org/", "Dev"),
+		bm("News", "https://news.example.com/", "Reading"),
+		bm("Dup", "https://dup.example.com/", "A"),
+		bm("Dup", "https://dup.example.com/", "B"),
+	)
+	to := collectionOf(
+		bm("The Go Programming Language", "https://go.dev/", "Dev"),
+		bm("Rust", "https://rust-lang.org/", "Dev", "Systems"),
+		bm("Dup", "https://dup.example.com/", "B"),
+		bm("Zig", "https://ziglang.org/", "Dev"),
+	)
+
+	d := Compare(from, to)
+
+	if len(d.Added) != 1 || d.Added[0].Title != "Zig" {
+		t.Errorf("Added = %v", d.Added)
+	}
+	if len(d.Removed) != 2 || d.Removed[0].Title != "News" || d.Removed[1].FolderPath[0] != "A" {
+		t.Errorf("Removed = %v", d.Removed)
+	}
+	if len(d.Moved) != 1 || d.Moved[0].New.Title != "Rust" {
+		t.Errorf("Moved = %v", d.Moved)
+	}
+	if len(d.Retitled) != 1 || d.Retitled[0].Old.Title != "Go" {
+		t.Errorf("Retitled = %v", d.Retitled)
+	}
+
+	if !Compare(from, from).Empty() {
+		t.Error("comparing a collection with itself is not empty")
+	}
+
+	data, err := Render(d, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"added": [`, `"title": "Zig"`, `"folder": [`, `"created_at":`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON lacks %s:\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), `"Title"`) || strings.Contains(string(data), `"FolderPath"`) {
+		t.Errorf("JSON has Go field names:\n%s", data)
+	}
+}
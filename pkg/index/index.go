@@ -0,0 +1,475 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package index maintains a persistent inverted index of bookmarks.
+//
+// The index stores the filtered bookmarks of every input together with
+// a signature of the input's files (size and modification time), so a
+// sync only re-reads inputs whose files changed. Searches look up query
+// tokens in the index with exact, prefix and fuzzy (edit distance)
+// matching instead of scanning every bookmark:
+//
+//	ix, err := index.Open(cfg.IndexPath())
+//	if err != nil {
+//	    return err
+//	}
+//	if _, err := index.Sync(ctx, ix, pipeline.New(cfg)); err != nil {
+//	    return err
+//	}
+//	q, _ := bookmark.ParseQuery("tag:go kubernets")
+//	results, total := ix.Search(q, bookmark.SearchOptions{Limit: 20})
+package index
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+)
+
+// fileName is the index file within the index directory.
+const fileName = "bookmarks.gob"
+
+// formatVersion is bumped whenever the on-disk format changes; indexes
+// with another version are discarded and rebuilt.
+const formatVersion = 2
+
+// Token fields, recorded per posting for ranking.
+const (
+	fieldTitle uint8 = 1 << iota
+	fieldURL
+	fieldFolder
+	fieldTag
+	fieldDescription
+)
+
+// posting records that a document contains a token.
+type posting struct {
+	Doc    int32
+	Fields uint8
+}
+
+// doc is an indexed bookmark.
+type doc struct {
+	Bookmark bookmark.Bookmark
+	Deleted  bool
+}
+
+// sourceEntry is the indexed state of one input.
+type sourceEntry struct {
+	Key       string
+	Signature string
+	Info      bookmark.SourceInfo
+	Docs      []int32
+}
+
+// indexData is the persisted form of the index.
+type indexData struct {
+	Version  int
+	Filter   string
+	Sources  []*sourceEntry
+	Docs     []doc
+	Postings map[string][]posting
+}
+
+// Index is an inverted index of bookmarks, grouped by source.
+// It is safe for concurrent use.
+type Index struct {
+	dir string
+
+	mu      sync.RWMutex
+	data    indexData
+	terms   []string // sorted vocabulary, for prefix and fuzzy lookups
+	deleted int
+	dirty   bool
+	merge   *bookmark.MergeOptions
+
+	// Maintained by regroup while merge is set
+	docKeys []string                     // Merge key by document
+	merged  map[string]bookmark.Bookmark // Merged bookmark by merge key
+}
+
+// New creates an empty index that will be saved to dir.
+func New(dir string) *Index {
+	ix := &Index{dir: dir}
+	ix.reset()
+	return ix
+}
+
+// Open loads the index stored in dir. A missing, unreadable or outdated
+// index yields an empty index, which the next Sync rebuilds.
+func Open(dir string) (*Index, error) {
+	ix := New(dir)
+
+	data, err := os.ReadFile(filepath.Join(dir, fileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ix, nil
+		}
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+
+	var loaded indexData
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&loaded); err != nil || loaded.Version != formatVersion {
+		// Start over rather than fail; the index is only a cache
+		ix.dirty = true
+		return ix, nil
+	}
+	if loaded.Postings == nil {
+		loaded.Postings = make(map[string][]posting)
+	}
+
+	ix.data = loaded
+	for _, d := range loaded.Docs {
+		if d.Deleted {
+			ix.deleted++
+		}
+	}
+	ix.rebuildTerms()
+	return ix, nil
+}
+
+// Save writes the index to its directory if it has changed.
+func (ix *Index) Save() error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if !ix.dirty {
+		return nil
+	}
+
+	if err := os.MkdirAll(ix.dir, 0755); err != nil {
+		return fmt.Errorf("creating index directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&ix.data); err != nil {
+		return fmt.Errorf("encoding index: %w", err)
+	}
+
+	// Write atomically so a concurrent reader never sees a partial file
+	tmp, err := os.CreateTemp(ix.dir, fileName+".*")
+	if err != nil {
+		return fmt.Errorf("writing index: %w", err)
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(ix.dir, fileName)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing index: %w", err)
+	}
+
+	ix.dirty = false
+	return nil
+}
+
+// Reset empties the index.
+func (ix *Index) Reset() {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.reset()
+	ix.dirty = true
+}
+
+func (ix *Index) reset() {
+	ix.data = indexData{
+		Version:  formatVersion,
+		Postings: make(map[string][]posting),
+	}
+	ix.terms = nil
+	ix.deleted = 0
+	ix.regroup()
+}
+
+// Len returns the number of indexed bookmarks.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.data.Docs) - ix.deleted
+}
+
+// SetFilter records a fingerprint of the filter rules the indexed
+// bookmarks passed and the transformations applied to them. A different
+// fingerprint empties the index, since the stored bookmarks no longer
+// reflect the rules.
+func (ix *Index) SetFilter(fingerprint string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.data.Filter == fingerprint {
+		return
+	}
+	ix.reset()
+	ix.data.Filter = fingerprint
+	ix.dirty = true
+}
+
+// SetDeduplicate makes Search merge bookmarks whose URLs share a key
+// with bookmark.Merge, matching the pipeline's deduplicate transform.
+// A merged bookmark matches if any of its duplicates does. Nil options
+// turn deduplication off.
+func (ix *Index) SetDeduplicate(opts *bookmark.MergeOptions) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.merge = opts
+	ix.regroup()
+}
+
+// regroup merges the indexed bookmarks that share a merge key, so Search
+// need not. It runs whenever the documents or merge options change.
+func (ix *Index) regroup() {
+	ix.docKeys, ix.merged = nil, nil
+	if ix.merge == nil {
+		return
+	}
+	key := ix.merge.Key
+	if key == nil {
+		key = func(url string) string { return url }
+	}
+
+	ix.docKeys = make([]string, len(ix.data.Docs))
+	var order []string
+	groups := make(map[string][]bookmark.Bookmark)
+	for _, src := range ix.data.Sources {
+		for _, id := range src.Docs {
+			b := ix.data.Docs[id].Bookmark
+			k := key(b.URL)
+			ix.docKeys[id] = k
+			if _, ok := groups[k]; !ok {
+				order = append(order, k)
+			}
+			groups[k] = append(groups[k], b)
+		}
+	}
+
+	ix.merged = make(map[string]bookmark.Bookmark, len(order))
+	for _, k := range order {
+		ix.merged[k] = bookmark.Merge(groups[k], *ix.merge)[0]
+	}
+}
+
+// Signature returns the stored signature of a source.
+func (ix *Index) Signature(key string) (string, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if src := ix.source(key); src != nil {
+		return src.Signature, true
+	}
+	return "", false
+}
+
+// Update replaces the bookmarks of a source.
+func (ix *Index) Update(key, signature string, info bookmark.SourceInfo, bookmarks []bookmark.Bookmark) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	src := ix.source(key)
+	if src == nil {
+		src = &sourceEntry{Key: key}
+		ix.data.Sources = append(ix.data.Sources, src)
+	} else {
+		ix.removeDocs(src.Docs)
+	}
+
+	src.Signature = signature
+	src.Info = info
+	src.Info.Count = len(bookmarks)
+	src.Docs = make([]int32, 0, len(bookmarks))
+	for _, b := range bookmarks {
+		src.Docs = append(src.Docs, ix.addDoc(b))
+	}
+
+	ix.maybeCompact()
+	ix.rebuildTerms()
+	ix.regroup()
+	ix.dirty = true
+}
+
+// Retain drops sources not named in keys and orders the remaining
+// sources as in keys.
+func (ix *Index) Retain(keys []string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	order := make(map[string]int, len(keys))
+	for i, k := range keys {
+		order[k] = i
+	}
+
+	var kept []*sourceEntry
+	removed := false
+	for _, src := range ix.data.Sources {
+		if _, ok := order[src.Key]; ok {
+			kept = append(kept, src)
+		} else {
+			ix.removeDocs(src.Docs)
+			removed = true
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		return order[kept[i].Key] < order[kept[j].Key]
+	})
+
+	reordered := false
+	for i := range kept {
+		if kept[i] != ix.data.Sources[i] {
+			reordered = true
+			ix.dirty = true
+			break
+		}
+	}
+	ix.data.Sources = kept
+
+	if removed {
+		ix.maybeCompact()
+		ix.rebuildTerms()
+		ix.dirty = true
+	}
+	if removed || reordered {
+		ix.regroup()
+	}
+}
+
+// Collection returns the indexed bookmarks in source order.
+func (ix *Index) Collection() *bookmark.Collection {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	c := bookmark.NewCollection()
+	for _, src := range ix.data.Sources {
+		if len(src.Docs) == 0 {
+			continue
+		}
+		bookmarks := make([]bookmark.Bookmark, 0, len(src.Docs))
+		for _, id := range src.Docs {
+			bookmarks = append(bookmarks, ix.data.Docs[id].Bookmark)
+		}
+		c.Add(bookmarks, src.Info)
+	}
+	return c
+}
+
+func (ix *Index) source(key string) *sourceEntry {
+	for _, src := range ix.data.Sources {
+		if src.Key == key {
+			return src
+		}
+	}
+	return nil
+}
+
+func (ix *Index) addDoc(b bookmark.Bookmark) int32 {
+	id := int32(len(ix.data.Docs))
+	ix.data.Docs = append(ix.data.Docs, doc{Bookmark: b})
+	for tok, fields := range docTokens(b) {
+		ix.data.Postings[tok] = append(ix.data.Postings[tok], posting{Doc: id, Fields: fields})
+	}
+	return id
+}
+
+// removeDocs marks documents deleted and drops their postings.
+func (ix *Index) removeDocs(ids []int32) {
+	if len(ids) == 0 {
+		return
+	}
+
+	removed := make(map[int32]bool, len(ids))
+	tokens := make(map[string]bool)
+	for _, id := range ids {
+		removed[id] = true
+		ix.data.Docs[id].Deleted = true
+		ix.deleted++
+		for tok := range docTokens(ix.data.Docs[id].Bookmark) {
+			tokens[tok] = true
+		}
+	}
+
+	for tok := range tokens {
+		list := ix.data.Postings[tok]
+		kept := list[:0]
+		for _, p := range list {
+			if !removed[p.Doc] {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == 0 {
+			delete(ix.data.Postings, tok)
+		} else {
+			ix.data.Postings[tok] = kept
+		}
+	}
+}
+
+// maybeCompact renumbers documents once more than half are deleted.
+func (ix *Index) maybeCompact() {
+	if ix.deleted*2 <= len(ix.data.Docs) {
+		return
+	}
+
+	sources := ix.data.Sources
+	docs := ix.data.Docs
+	ix.data.Docs = nil
+	ix.data.Postings = make(map[string][]posting)
+	ix.deleted = 0
+	for _, src := range sources {
+		for i, id := range src.Docs {
+			src.Docs[i] = ix.addDoc(docs[id].Bookmark)
+		}
+	}
+}
+
+func (ix *Index) rebuildTerms() {
+	ix.terms = make([]string, 0, len(ix.data.Postings))
+	for tok := range ix.data.Postings {
+		ix.terms = append(ix.terms, tok)
+	}
+	sort.Strings(ix.terms)
+}
+
+// docTokens returns the tokens of a bookmark with the fields they occur in.
+func docTokens(b bookmark.Bookmark) map[string]uint8 {
+	tokens := make(map[string]uint8)
+	add := func(s string, field uint8) {
+		for _, tok := range Tokenize(s) {
+			tokens[tok] |= field
+		}
+	}
+
+	add(b.Title, fieldTitle)
+	for _, tok := range Tokenize(b.URL) {
+		switch tok {
+		case "http", "https", "www":
+			continue
+		}
+		tokens[tok] |= fieldURL
+	}
+	add(strings.Join(b.FolderPath, " "), fieldFolder)
+	for _, tag := range b.Tags {
+		add(tag, fieldTag)
+	}
+	add(b.Description, fieldDescription)
+	return tokens
+}
@@ -0,0 +1,290 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+)
+
+var chromeBookmarks = []bookmark.Bookmark{
+	{Title: "Kubernetes Documentation", URL: "https://kubernetes.io/docs/", FolderPath: []string{"Dev", "Ops"}, Source: "chrome"},
+	{Title: "The Go Programming Language", URL: "https://go.dev/", FolderPath: []string{"Dev"}, Source: "chrome", Tags: []string{"golang"}},
+	{Title: "Getting started with Go modules", URL: "https://example.com/modules", FolderPath: []string{"Reading"}, Source: "chrome"},
+	{Title: "Runbook", URL: "https://wiki.example.com/runbook", FolderPath: []string{"Oncall"}, Source: "chrome", Description: "Pager escalation for cluster outages"},
+}
+
+var firefoxBookmarks = []bookmark.Bookmark{
+	{Title: "Awesome Go", URL: "https://github.com/avelino/awesome-go", FolderPath: []string{"Dev"}, Source: "firefox"},
+	{Title: "Go", URL: "https://go.dev/", FolderPath: []string{"Toolbar"}, Source: "firefox"},
+}
+
+func newTestIndex(t *testing.T) *Index {
+	t.Helper()
+	ix := New(t.TempDir())
+	ix.Update("chrome", "sig1", bookmark.SourceInfo{Name: "chrome"}, chromeBookmarks)
+	ix.Update("firefox", "sig1", bookmark.SourceInfo{Name: "firefox"}, firefoxBookmarks)
+	return ix
+}
+
+func search(t *testing.T, ix *Index, query string) []string {
+	t.Helper()
+	q, err := bookmark.ParseQuery(query)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q): %v", query, err)
+	}
+	results, _ := ix.Search(q, bookmark.SearchOptions{})
+	var titles []string
+	for _, r := range results {
+		titles = append(titles, r.Bookmark.Title)
+	}
+	return titles
+}
+
+func TestIndex_Search(t *testing.T) {
+	ix := newTestIndex(t)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"kubernetes", []string{"Kubernetes Documentation"}},
+		{"kube", []string{"Kubernetes Documentation"}},       // prefix
+		{"kubernets", []string{"Kubernetes Documentation"}},  // one edit
+		{"kuberentes", []string{"Kubernetes Documentation"}}, // two edits, long word
+		{"golang", []string{"The Go Programming Language"}},  // tag
+		{"ops", []string{"Kubernetes Documentation"}},        // folder
+		{"escalation", []string{"Runbook"}},                  // description
+		{"avelino", []string{"Awesome Go"}},                  // URL token
+		{`"go modules"`, []string{"Getting started with Go modules"}},
+		{`"pager escalation"`, []string{"Runbook"}},
+		{"go source:firefox", []string{"Go", "Awesome Go"}},
+		{"go -site:go.dev", []string{"Awesome Go", "Getting started with Go modules"}},
+		{"source:chrome folder:Dev", []string{"Kubernetes Documentation", "The Go Programming Language"}},
+		{"zzz", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := search(t, ix, tt.query)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndex_Deduplicate(t *testing.T) {
+	ix := newTestIndex(t)
+	ix.SetDeduplicate(&bookmark.MergeOptions{})
+
+	got := search(t, ix, "site:go.dev")
+	if len(got) != 1 || got[0] != "The Go Programming Language" {
+		t.Errorf("got %v, want the first go.dev bookmark only", got)
+	}
+	// Matching the dropped duplicate finds the bookmark that was kept
+	if got := search(t, ix, "toolbar"); len(got) != 1 || got[0] != "The Go Programming Language" {
+		t.Errorf("got %v, want the first go.dev bookmark", got)
+	}
+
+	ix.SetDeduplicate(&bookmark.MergeOptions{Strategy: bookmark.MergePreferSource, PreferSources: []string{"firefox"}})
+	q, _ := bookmark.ParseQuery("site:go.dev")
+	results, _ := ix.Search(q, bookmark.SearchOptions{})
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	b := results[0].Bookmark
+	if b.Title != "Go" || len(b.Occurrences) != 2 || len(b.Tags) != 1 || b.Tags[0] != "golang" {
+		t.Errorf("got %+v, want the firefox bookmark merged with chrome's", b)
+	}
+
+	// Updating a source regroups the duplicates
+	ix.Update("firefox", "sig2", bookmark.SourceInfo{Name: "firefox"}, firefoxBookmarks[:1])
+	if got := search(t, ix, "site:go.dev"); len(got) != 1 || got[0] != "The Go Programming Language" {
+		t.Errorf("got %v after removing the firefox duplicate", got)
+	}
+}
+
+func TestIndex_UpdateAndPersist(t *testing.T) {
+	ix := newTestIndex(t)
+
+	// Replacing a source drops its old bookmarks
+	ix.Update("chrome", "sig2", bookmark.SourceInfo{Name: "chrome"}, chromeBookmarks[:1])
+	if got := search(t, ix, "golang"); got != nil {
+		t.Errorf("stale bookmark still indexed: %v", got)
+	}
+	if ix.Len() != 3 {
+		t.Errorf("Len = %d, want 3", ix.Len())
+	}
+
+	if err := ix.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Open(ix.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig, ok := loaded.Signature("chrome"); !ok || sig != "sig2" {
+		t.Errorf("Signature = %q, %v", sig, ok)
+	}
+	if got := search(t, loaded, "kubernets"); len(got) != 1 {
+		t.Errorf("loaded index search = %v", got)
+	}
+
+	c := loaded.Collection()
+	if c.Count() != 3 || len(c.Sources) != 2 || c.Sources[0].Count != 1 {
+		t.Errorf("Collection = %d bookmarks, sources %+v", c.Count(), c.Sources)
+	}
+
+	// Retain drops sources that are no longer read
+	loaded.Retain([]string{"firefox"})
+	if got := search(t, loaded, "kubernetes"); got != nil {
+		t.Errorf("removed source still searchable: %v", got)
+	}
+	if loaded.Len() != 2 {
+		t.Errorf("Len after Retain = %d, want 2", loaded.Len())
+	}
+}
+
+func TestIndex_SetFilter(t *testing.T) {
+	ix := newTestIndex(t)
+	ix.SetFilter("rules-a")
+	ix.Update("chrome", "sig", bookmark.SourceInfo{Name: "chrome"}, chromeBookmarks)
+
+	ix.SetFilter("rules-a")
+	if ix.Len() != len(chromeBookmarks) {
+		t.Errorf("same filter cleared the index")
+	}
+	ix.SetFilter("rules-b")
+	if ix.Len() != 0 {
+		t.Errorf("changed filter kept %d bookmarks", ix.Len())
+	}
+}
+
+func TestWithinDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want bool
+	}{
+		{"kitten", "sitten", 1, true},
+		{"kitten", "sitting", 2, false},
+		{"kitten", "sitting", 3, true},
+		{"go", "go", 0, true},
+		{"abc", "", 3, true},
+	}
+	for _, tt := range tests {
+		if got := withinDistance(tt.a, tt.b, tt.max); got != tt.want {
+			t.Errorf("withinDistance(%q, %q, %d) = %v", tt.a, tt.b, tt.max, got)
+		}
+	}
+}
+
+// syntheticBookmarks generates n bookmarks from a fixed vocabulary.
+func syntheticBookmarks(n int) []bookmark.Bookmark {
+	words := []string{
+		"kubernetes", "golang", "python", "rust", "docker", "terraform", "react",
+		"postgres", "redis", "kafka", "grafana", "prometheus", "linux", "network",
+		"security", "design", "testing", "release", "cloud", "storage", "search",
+		"index", "compiler", "runtime", "browser", "bookmark", "tutorial", "guide",
+	}
+	hosts := []string{"github.com", "example.com", "docs.rs", "go.dev", "medium.com", "news.ycombinator.com"}
+	folders := []string{"Dev", "Ops", "Reading", "Work", "Archive", "Tools"}
+
+	r := rand.New(rand.NewSource(1))
+	bookmarks := make([]bookmark.Bookmark, n)
+	for i := range bookmarks {
+		w1, w2, w3 := words[r.Intn(len(words))], words[r.Intn(len(words))], words[r.Intn(len(words))]
+		bookmarks[i] = bookmark.Bookmark{
+			Title:      fmt.Sprintf("%s %s %s %d", w1, w2, w3, i),
+			URL:        fmt.Sprintf("https://%s/%s/%s-%d", hosts[r.Intn(len(hosts))], w1, w2, i),
+			FolderPath: []string{folders[r.Intn(len(folders))], folders[r.Intn(len(folders))]},
+			DateAdded:  time.Unix(int64(1600000000+i*60), 0),
+			Source:     "chrome",
+			Tags:       []string{w3},
+		}
+	}
+	return bookmarks
+}
+
+const benchmarkSize = 100000
+
+func benchmarkIndex(b *testing.B) *Index {
+	b.Helper()
+	ix := New(b.TempDir())
+	ix.Update("chrome", "sig", bookmark.SourceInfo{Name: "chrome"}, syntheticBookmarks(benchmarkSize))
+	return ix
+}
+
+func BenchmarkIndex_Build(b *testing.B) {
+	bookmarks := syntheticBookmarks(benchmarkSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix := New(b.TempDir())
+		ix.Update("chrome", "sig", bookmark.SourceInfo{Name: "chrome"}, bookmarks)
+	}
+}
+
+func BenchmarkIndex_SaveOpen(b *testing.B) {
+	ix := benchmarkIndex(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix.dirty = true
+		if err := ix.Save(); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := Open(ix.dir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkSearch(b *testing.B, query string) {
+	ix := benchmarkIndex(b)
+	q, _ := bookmark.ParseQuery(query)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix.Search(q, bookmark.SearchOptions{Limit: 20})
+	}
+}
+
+func BenchmarkIndex_Search(b *testing.B)       { benchmarkSearch(b, "kubernetes grafana") }
+func BenchmarkIndex_SearchPrefix(b *testing.B) { benchmarkSearch(b, "prom") }
+func BenchmarkIndex_SearchFuzzy(b *testing.B)  { benchmarkSearch(b, "kubernets") }
+func BenchmarkIndex_SearchFields(b *testing.B) { benchmarkSearch(b, "rust site:docs.rs tag:cloud") }
+
+func BenchmarkIndex_SearchMerged(b *testing.B) {
+	ix := benchmarkIndex(b)
+	ix.SetDeduplicate(&bookmark.MergeOptions{Strategy: bookmark.MergeAll})
+	q, _ := bookmark.ParseQuery("kubernetes grafana")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix.Search(q, bookmark.SearchOptions{Limit: 20})
+	}
+}
+
+// BenchmarkLinearSearch is the in-memory scan the index replaces.
+func BenchmarkLinearSearch(b *testing.B) {
+	bookmarks := syntheticBookmarks(benchmarkSize)
+	q, _ := bookmark.ParseQuery("kubernetes grafana")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bookmark.Search(bookmarks, q, bookmark.SearchOptions{Limit: 20})
+	}
+}
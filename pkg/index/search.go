@@ -0,0 +1,230 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+)
+
+// Match weights for a query token against an indexed token. Matches in
+// a title count double, and matches only in a description half.
+const (
+	weightExact  = 2.0
+	weightPrefix = 1.0
+	weightFuzzy  = 0.5
+)
+
+// fieldWeight scales a match by the fields the token occurs in.
+func fieldWeight(fields uint8) float64 {
+	switch {
+	case fields&fieldTitle != 0:
+		return 2
+	case fields&^fieldDescription != 0:
+		return 1
+	default:
+		return 0.5
+	}
+}
+
+// Tokenize splits text into lower-case words of letters and digits.
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Search returns the indexed bookmarks matching q, most relevant first,
+// along with the total number of matches before paging.
+//
+// Free-text terms are matched token by token: a query token matches an
+// indexed word it equals or prefixes, or a word with the same first
+// letter within a small edit distance (one edit for tokens of four or
+// more letters, two for eight or more). Quoted phrases must also appear
+// verbatim. Fielded and negated terms are evaluated as in bookmark.Query.
+func (ix *Index) Search(q bookmark.Query, opts bookmark.SearchOptions) ([]bookmark.SearchResult, int) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	// Candidate documents and their token scores; nil means every document
+	var candidates map[int32]float64
+	for _, t := range q.Terms {
+		if t.Field != "" || t.Negate {
+			continue
+		}
+		for _, tok := range Tokenize(t.Value) {
+			matches := ix.lookup(tok)
+			if candidates == nil {
+				candidates = matches
+				continue
+			}
+			for id, score := range candidates {
+				if m, ok := matches[id]; ok {
+					candidates[id] = score + m
+				} else {
+					delete(candidates, id)
+				}
+			}
+		}
+	}
+
+	var results []bookmark.SearchResult
+	seen := make(map[string]bool)
+	for _, src := range ix.data.Sources {
+		for _, id := range src.Docs {
+			tokenScore, ok := candidates[id]
+			if candidates != nil && !ok {
+				continue
+			}
+
+			b := ix.data.Docs[id].Bookmark
+			if !matchRemaining(q, b) {
+				continue
+			}
+			if ix.merge != nil {
+				key := ix.docKeys[id]
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				b = ix.merged[key]
+			}
+
+			results = append(results, bookmark.SearchResult{
+				Bookmark: b,
+				Score:    q.Score(b) + tokenScore,
+			})
+		}
+	}
+
+	return bookmark.Rank(results, opts)
+}
+
+// matchRemaining checks the terms the token lookup does not settle:
+// fielded and negated terms, phrases, and free text without any tokens.
+func matchRemaining(q bookmark.Query, b bookmark.Bookmark) bool {
+	for _, t := range q.Terms {
+		if t.Field == "" && !t.Negate {
+			tokens := Tokenize(t.Value)
+			if len(tokens) == 1 && tokens[0] == t.Value {
+				continue // settled by the index
+			}
+			if len(tokens) > 0 && !strings.ContainsAny(t.Value, " \t") {
+				continue // e.g. go.dev: every token matched
+			}
+		}
+		if t.Match(b) == t.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns the documents containing a word matching tok, with the
+// best weight of any matching word.
+func (ix *Index) lookup(tok string) map[int32]float64 {
+	matches := make(map[int32]float64)
+	add := func(term string, weight float64) {
+		for _, p := range ix.data.Postings[term] {
+			w := weight * fieldWeight(p.Fields)
+			if w > matches[p.Doc] {
+				matches[p.Doc] = w
+			}
+		}
+	}
+
+	// Exact and prefix matches share a range of the sorted vocabulary
+	i := sort.SearchStrings(ix.terms, tok)
+	for ; i < len(ix.terms) && strings.HasPrefix(ix.terms[i], tok); i++ {
+		if ix.terms[i] == tok {
+			add(tok, weightExact)
+		} else {
+			add(ix.terms[i], weightPrefix)
+		}
+	}
+
+	maxDist := maxEdits(tok)
+	if maxDist == 0 {
+		return matches
+	}
+
+	// Typos rarely hit the first letter; requiring it to match limits
+	// the scan to one range of the vocabulary
+	_, size := utf8.DecodeRuneInString(tok)
+	first := tok[:size]
+	for i := sort.SearchStrings(ix.terms, first); i < len(ix.terms); i++ {
+		term := ix.terms[i]
+		if !strings.HasPrefix(term, first) {
+			break
+		}
+		if strings.HasPrefix(term, tok) {
+			continue
+		}
+		if d := len(term) - len(tok); d > maxDist || d < -maxDist {
+			continue
+		}
+		if withinDistance(tok, term, maxDist) {
+			add(term, weightFuzzy)
+		}
+	}
+	return matches
+}
+
+// maxEdits returns the edit distance tolerated for a query token.
+func maxEdits(tok string) int {
+	switch n := len([]rune(tok)); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// withinDistance reports whether the Levenshtein distance between a and
+// b is at most max, giving up as soon as every path exceeds it.
+func withinDistance(a, b string, max int) bool {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if cur[j] < rowMin {
+				rowMin = cur[j]
+			}
+		}
+		if rowMin > max {
+			return false
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)] <= max
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+	"github.com/cloudygreybeard/favs/pkg/pipeline"
+)
+
+// Sync brings the index up to date with the inputs p reads in
+// all-inputs mode, re-reading only inputs whose files changed since the
+// last sync, then saves it. Changed inputs are read concurrently with
+// Pipeline.ReadInputs. Inputs that fail to read keep their previously
+// indexed bookmarks. It returns the number of inputs that were re-read.
+func Sync(ctx context.Context, ix *Index, p *pipeline.Pipeline) (int, error) {
+	cfg := p.Config()
+	t := cfg.Pipeline.Transform
+	ix.SetFilter(fmt.Sprintf("%+v %+v %+v %+v", p.FilterOptions(), t.Canonicalize, t.Tagging, t.Folders))
+	if t.Deduplicate {
+		opts := p.MergeOptions()
+		ix.SetDeduplicate(&opts)
+	} else {
+		ix.SetDeduplicate(nil)
+	}
+
+	names := p.Inputs()
+	var changed, sigs []string
+	for _, name := range names {
+		sig := signature(p.InputPaths(name))
+		if cur, ok := ix.Signature(name); ok && cur == sig {
+			continue
+		}
+		changed = append(changed, name)
+		sigs = append(sigs, sig)
+	}
+
+	results, _ := p.ReadInputs(ctx, changed)
+	updated := 0
+	for i, c := range results {
+		if c == nil {
+			continue
+		}
+		filtered := p.Filter(c)
+
+		info := bookmark.SourceInfo{Name: changed[i]}
+		if len(c.Sources) > 0 {
+			info = c.Sources[0]
+		}
+		ix.Update(changed[i], sigs[i], info, p.TransformBookmarks(filtered.Bookmarks))
+		updated++
+	}
+	ix.Retain(names)
+
+	return updated, ix.Save()
+}
+
+// signature summarises the size and modification time of files.
+func signature(paths []string) string {
+	var sb strings.Builder
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(&sb, "%s:-;", path)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+	}
+	return sb.String()
+}
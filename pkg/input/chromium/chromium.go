@@ -18,9 +18,12 @@ package chromium
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -30,61 +33,127 @@ import (
 
 	"github.com/cloudygreybeard/favs/pkg/adapter"
 	"github.com/cloudygreybeard/favs/pkg/bookmark"
+	"github.com/cloudygreybeard/favs/pkg/diag"
 	"github.com/cloudygreybeard/favs/pkg/input"
 )
 
-// chromiumPaths maps browser names to their config directories per platform.
-var chromiumPaths = map[string]map[string]string{
+// chromiumPaths maps browser names to their user data directories per
+// platform, in the order they are probed. Paths are relative to the home
+// directory, or to %LOCALAPPDATA% on Windows; a leading "~/" is always
+// relative to the home directory. On Linux the later entries cover Snap
+// and Flatpak installs.
+var chromiumPaths = map[string]map[string][]string{
 	"chrome": {
-		"linux":   ".config/google-chrome",
-		"darwin":  "Library/Application Support/Google/Chrome",
-		"windows": "Google/Chrome/User Data",
+		"linux":   {".config/google-chrome", ".var/app/com.google.Chrome/config/google-chrome"},
+		"darwin":  {"Library/Application Support/Google/Chrome"},
+		"windows": {"Google/Chrome/User Data"},
+	},
+	"chrome-beta": {
+		"linux":   {".config/google-chrome-beta", ".var/app/com.google.ChromeBeta/config/google-chrome-beta"},
+		"darwin":  {"Library/Application Support/Google/Chrome Beta"},
+		"windows": {"Google/Chrome Beta/User Data"},
+	},
+	"chrome-dev": {
+		"linux":   {".config/google-chrome-unstable", ".var/app/com.google.ChromeDev/config/google-chrome-unstable"},
+		"darwin":  {"Library/Application Support/Google/Chrome Dev"},
+		"windows": {"Google/Chrome Dev/User Data"},
+	},
+	"chrome-canary": {
+		"linux":   {".config/google-chrome-canary"},
+		"darwin":  {"Library/Application Support/Google/Chrome Canary"},
+		"windows": {"Google/Chrome SxS/User Data"},
 	},
 	"edge": {
-		"linux":   ".config/microsoft-edge",
-		"darwin":  "Library/Application Support/Microsoft Edge",
-		"windows": "Microsoft/Edge/User Data",
+		"linux":   {".config/microsoft-edge", ".var/app/com.microsoft.Edge/config/microsoft-edge"},
+		"darwin":  {"Library/Application Support/Microsoft Edge"},
+		"windows": {"Microsoft/Edge/User Data"},
 	},
 	"chromium": {
-		"linux":   ".config/chromium",
-		"darwin":  "Library/Application Support/Chromium",
-		"windows": "Chromium/User Data",
+		"linux": {
+			".config/chromium",
+			"snap/chromium/common/chromium",
+			".var/app/org.chromium.Chromium/config/chromium",
+		},
+		"darwin":  {"Library/Application Support/Chromium"},
+		"windows": {"Chromium/User Data"},
 	},
 	"brave": {
-		"linux":   ".config/BraveSoftware/Brave-Browser",
-		"darwin":  "Library/Application Support/BraveSoftware/Brave-Browser",
-		"windows": "BraveSoftware/Brave-Browser/User Data",
+		"linux": {
+			".config/BraveSoftware/Brave-Browser",
+			"snap/brave/current/.config/BraveSoftware/Brave-Browser",
+			".var/app/com.brave.Browser/config/BraveSoftware/Brave-Browser",
+		},
+		"darwin":  {"Library/Application Support/BraveSoftware/Brave-Browser"},
+		"windows": {"BraveSoftware/Brave-Browser/User Data"},
+	},
+	"vivaldi": {
+		"linux":   {".config/vivaldi", "snap/vivaldi/current/.config/vivaldi", ".var/app/com.vivaldi.Vivaldi/config/vivaldi"},
+		"darwin":  {"Library/Application Support/Vivaldi"},
+		"windows": {"Vivaldi/User Data"},
+	},
+	"opera": {
+		"linux":   {".config/opera", "snap/opera/current/.config/opera", ".var/app/com.opera.Opera/config/opera"},
+		"darwin":  {"Library/Application Support/com.operasoftware.Opera"},
+		"windows": {"~/AppData/Roaming/Opera Software/Opera Stable"},
+	},
+	"opera-gx": {
+		"darwin":  {"Library/Application Support/com.operasoftware.OperaGX"},
+		"windows": {"~/AppData/Roaming/Opera Software/Opera GX Stable"},
+	},
+	"yandex": {
+		"linux":   {".config/yandex-browser"},
+		"darwin":  {"Library/Application Support/Yandex/YandexBrowser"},
+		"windows": {"Yandex/YandexBrowser/User Data"},
+	},
+	"arc": {
+		"darwin": {"Library/Application Support/Arc/User Data"},
 	},
 }
 
 var displayNames = map[string]string{
-	"chrome":   "Google Chrome",
-	"edge":     "Microsoft Edge",
-	"chromium": "Chromium",
-	"brave":    "Brave",
+	"chrome":        "Google Chrome",
+	"chrome-beta":   "Google Chrome Beta",
+	"chrome-dev":    "Google Chrome Dev",
+	"chrome-canary": "Google Chrome Canary",
+	"edge":          "Microsoft Edge",
+	"chromium":      "Chromium",
+	"brave":         "Brave",
+	"vivaldi":       "Vivaldi",
+	"opera":         "Opera",
+	"opera-gx":      "Opera GX",
+	"yandex":        "Yandex Browser",
+	"arc":           "Arc",
 }
 
+// rootOrder is the order in which bookmark roots are read; any others
+// follow in name order.
+var rootOrder = []string{"bookmark_bar", "other", "synced"}
+
 // Difference between Chrome epoch (1601-01-01) and Unix epoch (1970-01-01) in seconds
 const chromeToUnixEpochDelta = 11644473600
 
 func init() {
 	// Register all Chromium-based browser adapters
-	adapter.RegisterInput(New("chrome"))
-	adapter.RegisterInput(New("edge"))
-	adapter.RegisterInput(New("chromium"))
-	adapter.RegisterInput(New("brave"))
+	for _, browser := range []string{
+		"chrome", "chrome-beta", "chrome-dev", "chrome-canary", "edge", "chromium",
+		"brave", "vivaldi", "opera", "opera-gx", "yandex", "arc",
+	} {
+		adapter.RegisterInputFactory(func() input.Adapter { return New(browser) })
+	}
 }
 
 // Adapter implements input.Adapter for Chromium-based browsers.
 type Adapter struct {
 	browser  string
 	config   input.Config
+	root     string // User data directory the profiles were found in
 	profiles []profileInfo
 }
 
 type profileInfo struct {
-	name string
-	path string
+	name        string // Directory name, such as "Profile 1"
+	displayName string // Name from Local State, such as "Work"
+	path        string
 }
 
 // New creates a new Chromium adapter for the specified browser.
@@ -141,19 +210,32 @@ func (a *Adapter) Path() string {
 
 	var names []string
 	for _, p := range a.profiles {
-		names = append(names, p.name)
+		names = append(names, bookmark.ProfileLabel(p.name, p.displayName))
 	}
 	return a.basePath() + " [" + strings.Join(names, ", ") + "]"
 }
 
+// WatchPaths returns the Bookmarks file of each profile.
+func (a *Adapter) WatchPaths() []string {
+	if a.config.CustomPath != "" {
+		return []string{a.config.CustomPath}
+	}
+	var paths []string
+	for _, p := range a.profiles {
+		paths = append(paths, p.path)
+	}
+	return paths
+}
+
 // ListProfiles returns available profiles.
 func (a *Adapter) ListProfiles() ([]input.ProfileInfo, error) {
 	var result []input.ProfileInfo
 	for i, p := range a.profiles {
 		result = append(result, input.ProfileInfo{
-			Name:      p.name,
-			Path:      p.path,
-			IsDefault: i == 0 || p.name == "Default",
+			Name:        p.name,
+			DisplayName: p.displayName,
+			Path:        p.path,
+			IsDefault:   i == 0 || p.name == "Default",
 		})
 	}
 	return result, nil
@@ -162,7 +244,7 @@ func (a *Adapter) ListProfiles() ([]input.ProfileInfo, error) {
 // Read returns all bookmarks from the browser.
 func (a *Adapter) Read(ctx context.Context) ([]bookmark.Bookmark, error) {
 	if a.config.CustomPath != "" {
-		return a.readFromPath(a.config.CustomPath, "custom")
+		return a.readFromPath(profileInfo{name: "custom", path: a.config.CustomPath})
 	}
 
 	if len(a.profiles) == 0 {
@@ -171,26 +253,33 @@ func (a *Adapter) Read(ctx context.Context) ([]bookmark.Bookmark, error) {
 
 	// If specific profile requested, find it
 	if a.config.Profile != "" {
-		for _, profile := range a.profiles {
-			if profile.name == a.config.Profile {
-				return a.readFromPath(profile.path, profile.name)
-			}
+		if profile, ok := a.findProfile(a.config.Profile); ok {
+			return a.readFromPath(profile)
 		}
 
 		// If "Default" was requested but not found, use first available
 		if a.config.Profile == "Default" && len(a.profiles) > 0 {
-			first := a.profiles[0]
-			return a.readFromPath(first.path, first.name)
+			return a.readFromPath(a.profiles[0])
 		}
 
+		diag.Report(ctx, diag.Diagnostic{
+			Severity: diag.Error,
+			Profile:  a.config.Profile,
+			Message:  "profile not found",
+		})
 		return nil, nil
 	}
 
 	// No profile specified: read all profiles
 	var allBookmarks []bookmark.Bookmark
 	for _, profile := range a.profiles {
-		bookmarks, err := a.readFromPath(profile.path, profile.name)
+		bookmarks, err := a.readFromPath(profile)
 		if err != nil {
+			diag.Report(ctx, diag.Diagnostic{
+				Severity: diag.Error,
+				Profile:  profile.name,
+				Message:  fmt.Sprintf("skipping profile: %v", err),
+			})
 			continue
 		}
 		allBookmarks = append(allBookmarks, bookmarks...)
@@ -199,43 +288,89 @@ func (a *Adapter) Read(ctx context.Context) ([]bookmark.Bookmark, error) {
 	return allBookmarks, nil
 }
 
-func (a *Adapter) basePath() string {
-	paths, ok := chromiumPaths[a.browser]
-	if !ok {
-		return ""
+// findProfile finds a profile by directory name or, failing that, by the
+// name the user gave it.
+func (a *Adapter) findProfile(name string) (profileInfo, bool) {
+	for _, p := range a.profiles {
+		if p.name == name {
+			return p, true
+		}
+	}
+	for _, p := range a.profiles {
+		if p.displayName != "" && strings.EqualFold(p.displayName, name) {
+			return p, true
+		}
 	}
+	return profileInfo{}, false
+}
 
-	relPath, ok := paths[runtime.GOOS]
-	if !ok {
-		return ""
+// basePath returns the user data directory profiles were found in, or
+// else the first candidate.
+func (a *Adapter) basePath() string {
+	if a.root != "" {
+		return a.root
+	}
+	if roots := a.candidateRoots(); len(roots) > 0 {
+		return roots[0]
 	}
+	return ""
+}
 
-	var base string
+// candidateRoots returns the user data directories to probe.
+func (a *Adapter) candidateRoots() []string {
+	home, _ := os.UserHomeDir()
+	base := home
 	if runtime.GOOS == "windows" {
 		base = os.Getenv("LOCALAPPDATA")
-	} else {
-		base, _ = os.UserHomeDir()
 	}
 
-	return filepath.Join(base, relPath)
+	var roots []string
+	for _, rel := range chromiumPaths[a.browser][runtime.GOOS] {
+		if rest, ok := strings.CutPrefix(rel, "~/"); ok {
+			roots = append(roots, filepath.Join(home, rest))
+		} else {
+			roots = append(roots, filepath.Join(base, rel))
+		}
+	}
+	return roots
 }
 
+// discoverProfiles returns the profiles of the first user data directory
+// that has any, so a native install shadows a Snap or Flatpak one.
 func (a *Adapter) discoverProfiles() []profileInfo {
+	a.root = ""
 	if a.config.CustomPath != "" {
 		return nil
 	}
 
-	basePath := a.basePath()
-	if basePath == "" {
-		return nil
+	for _, root := range a.candidateRoots() {
+		if profiles := scanProfiles(root); len(profiles) > 0 {
+			a.root = root
+			return profiles
+		}
 	}
+	return nil
+}
 
-	entries, err := os.ReadDir(basePath)
+// scanProfiles lists the profiles of a user data directory: those Local
+// State knows, with the names the user gave them, and any other Default
+// or Profile N directories. Opera keeps its main profile in the directory
+// itself, which is listed as Default.
+func scanProfiles(root string) []profileInfo {
+	entries, err := os.ReadDir(root)
 	if err != nil {
 		return nil
 	}
+	names := readProfileNames(root)
 
 	var profiles []profileInfo
+	if _, err := os.Stat(filepath.Join(root, "Bookmarks")); err == nil {
+		profiles = append(profiles, profileInfo{
+			name:        "Default",
+			displayName: names["Default"],
+			path:        filepath.Join(root, "Bookmarks"),
+		})
+	}
 
 	for _, entry := range entries {
 		if !entry.IsDir() {
@@ -243,13 +378,15 @@ func (a *Adapter) discoverProfiles() []profileInfo {
 		}
 
 		name := entry.Name()
+		displayName, known := names[name]
 
-		if name == "Default" || strings.HasPrefix(name, "Profile ") {
-			bookmarkPath := filepath.Join(basePath, name, "Bookmarks")
+		if known || name == "Default" || strings.HasPrefix(name, "Profile ") {
+			bookmarkPath := filepath.Join(root, name, "Bookmarks")
 			if _, err := os.Stat(bookmarkPath); err == nil {
 				profiles = append(profiles, profileInfo{
-					name: name,
-					path: bookmarkPath,
+					name:        name,
+					displayName: displayName,
+					path:        bookmarkPath,
 				})
 			}
 		}
@@ -258,8 +395,34 @@ func (a *Adapter) discoverProfiles() []profileInfo {
 	return profiles
 }
 
-func (a *Adapter) readFromPath(path, profile string) ([]bookmark.Bookmark, error) {
-	data, err := os.ReadFile(path)
+// readProfileNames returns the names of the profiles in root/Local State,
+// keyed by directory name. It returns nil if the file cannot be read.
+func readProfileNames(root string) map[string]string {
+	data, err := os.ReadFile(filepath.Join(root, "Local State"))
+	if err != nil {
+		return nil
+	}
+
+	var state struct {
+		Profile struct {
+			InfoCache map[string]struct {
+				Name string `json:"name"`
+			} `json:"info_cache"`
+		} `json:"profile"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+
+	names := make(map[string]string, len(state.Profile.InfoCache))
+	for dir, info := range state.Profile.InfoCache {
+		names[dir] = info.Name
+	}
+	return names
+}
+
+func (a *Adapter) readFromPath(profile profileInfo) ([]bookmark.Bookmark, error) {
+	data, err := os.ReadFile(profile.path)
 	if err != nil {
 		return nil, err
 	}
@@ -272,9 +435,9 @@ func (a *Adapter) readFromPath(path, profile string) ([]bookmark.Bookmark, error
 	}
 
 	var bookmarks []bookmark.Bookmark
-	for _, rootData := range chromiumData.Roots {
+	for _, name := range sortedRoots(chromiumData.Roots) {
 		var node chromiumNode
-		if err := json.Unmarshal(rootData, &node); err != nil {
+		if err := json.Unmarshal(chromiumData.Roots[name], &node); err != nil {
 			continue
 		}
 		if node.Type == "folder" {
@@ -285,6 +448,25 @@ func (a *Adapter) readFromPath(path, profile string) ([]bookmark.Bookmark, error
 	return bookmarks, nil
 }
 
+// sortedRoots returns the names of the bookmark roots in rootOrder, then
+// the rest in name order.
+func sortedRoots(roots map[string]json.RawMessage) []string {
+	var names []string
+	for _, name := range rootOrder {
+		if _, ok := roots[name]; ok {
+			names = append(names, name)
+		}
+	}
+	var rest []string
+	for name := range roots {
+		if !slices.Contains(rootOrder, name) {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
+}
+
 type chromiumNode struct {
 	Type      string         `json:"type"`
 	Name      string         `json:"name"`
@@ -293,7 +475,7 @@ type chromiumNode struct {
 	Children  []chromiumNode `json:"children"`
 }
 
-func (a *Adapter) parseFolder(node chromiumNode, path []string, profile string, bookmarks *[]bookmark.Bookmark) {
+func (a *Adapter) parseFolder(node chromiumNode, path []string, profile profileInfo, bookmarks *[]bookmark.Bookmark) {
 	currentPath := path
 	if node.Name != "" {
 		currentPath = append(append([]string{}, path...), node.Name)
@@ -303,12 +485,13 @@ func (a *Adapter) parseFolder(node chromiumNode, path []string, profile string,
 		switch child.Type {
 		case "url":
 			*bookmarks = append(*bookmarks, bookmark.Bookmark{
-				Title:      child.Name,
-				URL:        child.URL,
-				FolderPath: currentPath,
-				DateAdded:  parseChromiumDate(child.DateAdded),
-				Source:     a.browser,
-				Profile:    profile,
+				Title:       child.Name,
+				URL:         child.URL,
+				FolderPath:  currentPath,
+				DateAdded:   parseChromiumDate(child.DateAdded),
+				Source:      a.browser,
+				Profile:     profile.name,
+				ProfileName: profile.displayName,
 			})
 		case "folder":
 			a.parseFolder(child, currentPath, profile, bookmarks)
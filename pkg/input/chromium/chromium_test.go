@@ -0,0 +1,192 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chromium
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const bookmarksJSON = `{
+  "roots": {
+    "synced": {"type": "folder", "name": "Mobile bookmarks", "children": [
+      {"type": "url", "name": "Phone", "url": "https://example.com/phone"}
+    ]},
+    "other": {"type": "folder", "name": "Other bookmarks", "children": [
+      {"type": "url", "name": "Other", "url": "https://example.com/other"}
+    ]},
+    "bookmark_bar": {"type": "folder", "name": "Bookmarks bar", "children": [
+      {"type": "url", "name": "Bar", "url": "https://example.com/bar"}
+    ]}
+  },
+  "version": 1
+}`
+
+// fakeHome points the home directory at a temporary one.
+func fakeHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("LOCALAPPDATA", home)
+	return home
+}
+
+// writeBookmarks creates dir/Bookmarks, with dir relative to the home
+// directory like the entries of chromiumPaths.
+func writeBookmarks(t *testing.T, home, dir string) {
+	t.Helper()
+	dir = filepath.Join(home, strings.TrimPrefix(dir, "~/"))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Bookmarks"), []byte(bookmarksJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscoverProfiles_Paths(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Snap and Flatpak paths are Linux only")
+	}
+
+	tests := []struct {
+		browser string
+		dir     string // Profile directory, relative to the home directory
+		profile string
+	}{
+		{"chrome", ".config/google-chrome/Default", "Default"},
+		{"chrome", ".var/app/com.google.Chrome/config/google-chrome/Default", "Default"},
+		{"chrome-beta", ".config/google-chrome-beta/Profile 1", "Profile 1"},
+		{"chromium", "snap/chromium/common/chromium/Default", "Default"},
+		{"brave", ".var/app/com.brave.Browser/config/BraveSoftware/Brave-Browser/Default", "Default"},
+		{"vivaldi", ".config/vivaldi/Default", "Default"},
+		{"yandex", ".config/yandex-browser/Default", "Default"},
+		{"opera", ".config/opera", "Default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.browser+"/"+tt.dir, func(t *testing.T) {
+			home := fakeHome(t)
+			writeBookmarks(t, home, tt.dir)
+			// Profile-like directories without bookmarks are skipped
+			if err := os.MkdirAll(filepath.Join(home, ".config/google-chrome/Profile 9"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			a := New(tt.browser)
+			if !a.Available() {
+				t.Fatalf("%s not detected in %s", tt.browser, tt.dir)
+			}
+			if len(a.profiles) != 1 || a.profiles[0].name != tt.profile {
+				t.Errorf("profiles = %+v, want one named %q", a.profiles, tt.profile)
+			}
+		})
+	}
+}
+
+func TestDiscoverProfiles_NativeShadowsFlatpak(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Flatpak paths are Linux only")
+	}
+	home := fakeHome(t)
+	writeBookmarks(t, home, ".var/app/com.google.Chrome/config/google-chrome/Default")
+	writeBookmarks(t, home, ".config/google-chrome/Profile 2")
+
+	a := New("chrome")
+	if want := filepath.Join(home, ".config/google-chrome"); a.basePath() != want {
+		t.Errorf("basePath = %q, want %q", a.basePath(), want)
+	}
+	if len(a.profiles) != 1 || a.profiles[0].name != "Profile 2" {
+		t.Errorf("profiles = %+v", a.profiles)
+	}
+}
+
+func TestDiscoverProfiles_NotInstalled(t *testing.T) {
+	fakeHome(t)
+	for browser := range chromiumPaths {
+		if New(browser).Available() {
+			t.Errorf("%s detected in an empty home directory", browser)
+		}
+	}
+}
+
+func TestRead_RootOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeBookmarks(t, dir, "")
+
+	a := New("vivaldi")
+	a.config.CustomPath = filepath.Join(dir, "Bookmarks")
+	for i := 0; i < 5; i++ {
+		bookmarks, err := a.Read(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var titles []string
+		for _, b := range bookmarks {
+			titles = append(titles, b.Title)
+		}
+		if got := strings.Join(titles, ","); got != "Bar,Other,Phone" {
+			t.Fatalf("read order = %s, want Bar,Other,Phone", got)
+		}
+	}
+}
+
+func TestDiscoverProfiles_LocalStateNames(t *testing.T) {
+	home := fakeHome(t)
+	root := New("chrome").basePath()
+	rel, _ := filepath.Rel(home, root)
+	writeBookmarks(t, home, filepath.Join(rel, "Default"))
+	writeBookmarks(t, home, filepath.Join(rel, "Profile 3"))
+	writeBookmarks(t, home, filepath.Join(rel, "Work Stuff"))
+	writeBookmarks(t, home, filepath.Join(rel, "System Profile"))
+	state := `{"profile": {"info_cache": {
+		"Default": {"name": "Personal"},
+		"Profile 3": {"name": "Work"},
+		"Work Stuff": {"name": "Side project"}
+	}}}`
+	if err := os.WriteFile(filepath.Join(root, "Local State"), []byte(state), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := New("chrome")
+	profiles, err := a.ListProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range profiles {
+		got = append(got, p.Name+"="+p.DisplayName)
+	}
+	if want := "Default=Personal,Profile 3=Work,Work Stuff=Side project"; strings.Join(got, ",") != want {
+		t.Errorf("profiles = %s, want %s", strings.Join(got, ","), want)
+	}
+
+	a.config.Profile = "work"
+	bookmarks, err := a.Read(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bookmarks) == 0 {
+		t.Fatal("no bookmarks read for profile work")
+	}
+	if b := bookmarks[0]; b.Profile != "Profile 3" || b.ProfileName != "Work" {
+		t.Errorf("Profile/ProfileName = %q/%q, want Profile 3/Work", b.Profile, b.ProfileName)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firefox
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+)
+
+// backupPattern matches the bookmark backups Firefox keeps in a profile's
+// bookmarkbackups directory: bookmarks-2026-10-15_1234_<hash>.jsonlz4,
+// or bookmarks-2026-10-15.json from older versions.
+var backupPattern = regexp.MustCompile(`^bookmarks-(\d{4}-\d{2}-\d{2})(_.*)?\.(jsonlz4|json)$`)
+
+// Node types in a backup.
+const (
+	typeBookmark = 1
+	typeFolder   = 2
+)
+
+// descriptionAnno is the annotation holding a bookmark's description.
+const descriptionAnno = "bookmarkProperties/description"
+
+// backup is a bookmark backup file.
+type backup struct {
+	path string
+	date time.Time
+}
+
+// isBackupFile reports whether path names a bookmark backup, by its
+// extension.
+func isBackupFile(path string) bool {
+	return strings.HasSuffix(path, ".jsonlz4") || strings.HasSuffix(path, ".json")
+}
+
+// listBackups returns the backups in dir, oldest first.
+func listBackups(dir string) []backup {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var backups []backup
+	for _, entry := range entries {
+		m := backupPattern.FindStringSubmatch(entry.Name())
+		if m == nil || entry.IsDir() {
+			continue
+		}
+		date, err := time.ParseInLocation("2006-01-02", m[1], time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, entry.Name()), date: date})
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].date.Before(backups[j].date)
+	})
+	return backups
+}
+
+// closestBackup returns the backup nearest to date, preferring the
+// earlier of two equally near, or the newest if date is zero.
+func closestBackup(backups []backup, date time.Time) (backup, bool) {
+	if len(backups) == 0 {
+		return backup{}, false
+	}
+	if date.IsZero() {
+		return backups[len(backups)-1], true
+	}
+
+	best := backups[0]
+	for _, b := range backups[1:] {
+		if absDuration(b.date.Sub(date)) < absDuration(best.date.Sub(date)) {
+			best = b
+		}
+	}
+	return best, true
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// backupNode is a node of the JSON tree in a backup.
+type backupNode struct {
+	GUID      string       `json:"guid"`
+	Title     string       `json:"title"`
+	TypeCode  int          `json:"typeCode"`
+	Root      string       `json:"root"`
+	URI       string       `json:"uri"`
+	DateAdded int64        `json:"dateAdded"` // Microseconds since the Unix epoch
+	Tags      string       `json:"tags"`      // Comma-separated
+	Keyword   string       `json:"keyword"`
+	Annos     []backupAnno `json:"annos"`
+	Children  []backupNode `json:"children"`
+}
+
+type backupAnno struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+// readBackupFile reads the tree of a .jsonlz4 or plain .json backup.
+func readBackupFile(path string) (*backupNode, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(path, ".jsonlz4") {
+		if data, err = decodeMozLz4(data); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	var root backupNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Base(path), err)
+	}
+	return &root, nil
+}
+
+// readBackup returns the bookmarks in a backup. Like places.sqlite, the
+// folder paths start at the roots (menu, toolbar, unfiled, mobile) and
+// the tags folder is skipped, as each bookmark lists its own tags.
+func (a *Adapter) readBackup(path string) ([]bookmark.Bookmark, error) {
+	root, err := readBackupFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var bookmarks []bookmark.Bookmark
+	seen := make(map[string]bool)
+	var walk func(node backupNode, folders []string)
+	walk = func(node backupNode, folders []string) {
+		switch node.TypeCode {
+		case typeFolder:
+			if node.Root == "tagsFolder" {
+				return
+			}
+			path := folders
+			if node.Title != "" && node.Root != "placesRoot" {
+				path = append(append([]string(nil), folders...), node.Title)
+			}
+			for _, child := range node.Children {
+				walk(child, path)
+			}
+		case typeBookmark:
+			if node.URI == "" || strings.HasPrefix(node.URI, "place:") || seen[node.URI] {
+				return
+			}
+			seen[node.URI] = true
+			bookmarks = append(bookmarks, a.backupBookmark(node, folders))
+		}
+	}
+	walk(*root, nil)
+
+	return bookmarks, nil
+}
+
+// backupBookmark converts a bookmark node.
+func (a *Adapter) backupBookmark(node backupNode, folders []string) bookmark.Bookmark {
+	b := bookmark.Bookmark{
+		Title:      node.Title,
+		URL:        node.URI,
+		FolderPath: folders,
+		Source:     a.browser,
+		Profile:    a.profile,
+		GUID:       node.GUID,
+		Keyword:    node.Keyword,
+	}
+	if b.Title == "" {
+		b.Title = node.URI
+	}
+	if node.DateAdded > 0 {
+		b.DateAdded = time.UnixMicro(node.DateAdded)
+	}
+	for _, tag := range strings.Split(node.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			b.Tags = append(b.Tags, tag)
+		}
+	}
+	for _, anno := range node.Annos {
+		if s, ok := anno.Value.(string); ok && anno.Name == descriptionAnno {
+			b.Description = s
+		}
+	}
+	return b
+}
@@ -0,0 +1,240 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firefox
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudygreybeard/favs/pkg/input"
+)
+
+// backupJSON is a backup tree as Firefox writes it, trimmed.
+const backupJSON = `{"guid":"root________","title":"","index":0,"id":1,"typeCode":2,"type":"text/x-moz-place-container","root":"placesRoot","children":[
+ {"guid":"menu________","title":"menu","index":0,"id":2,"typeCode":2,"root":"bookmarksMenuFolder","children":[
+  {"guid":"Fq1fV0dY2l8k","title":"Recent Tags","id":9,"typeCode":1,"uri":"place:type=6&sort=14&maxResults=10"},
+  {"guid":"sep1________","id":10,"typeCode":3,"type":"text/x-moz-place-separator"},
+  {"guid":"aBcDeFgHiJkL","title":"Dev","id":11,"typeCode":2,"children":[
+   {"guid":"GoDocsGuid01","title":"Go docs","id":12,"typeCode":1,"type":"text/x-moz-place","uri":"https://pkg.go.dev/","dateAdded":1700000000000000,"tags":"go,docs","keyword":"gd",
+    "annos":[{"name":"bookmarkProperties/description","flags":0,"expires":4,"value":"Package docs"}]}
+  ]}
+ ]},
+ {"guid":"toolbar_____","title":"toolbar","index":1,"id":3,"typeCode":2,"root":"toolbarFolder","children":[
+  {"guid":"MozillaGuid1","title":"","id":13,"typeCode":1,"uri":"https://www.mozilla.org/"},
+  {"guid":"GoDocsGuid02","title":"Go docs again","id":14,"typeCode":1,"uri":"https://pkg.go.dev/"}
+ ]},
+ {"guid":"tags________","title":"tags","index":2,"id":4,"typeCode":2,"root":"tagsFolder","children":[
+  {"guid":"tagGo_______","title":"go","id":15,"typeCode":2,"children":[
+   {"guid":"tagGoItem___","title":"","id":16,"typeCode":1,"uri":"https://pkg.go.dev/"}
+  ]}
+ ]},
+ {"guid":"unfiled_____","title":"unfiled","index":3,"id":5,"typeCode":2,"root":"unfiledBookmarksFolder"}
+]}`
+
+// mozLz4 wraps data in mozLz4 framing as a single LZ4 sequence of
+// literals, which is a valid if uncompressed block.
+func mozLz4(data []byte) []byte {
+	out := append([]byte(nil), mozLz4Magic...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(data)))
+	if n := len(data); n < 15 {
+		out = append(out, byte(n<<4))
+	} else {
+		out = append(out, 0xf0)
+		for n -= 15; n >= 255; n -= 255 {
+			out = append(out, 255)
+		}
+		out = append(out, byte(n))
+	}
+	return append(out, data...)
+}
+
+func TestDecodeLZ4Block(t *testing.T) {
+	tests := []struct {
+		name  string
+		block []byte
+		size  int
+		want  string
+	}{
+		{"literals", []byte{0x50, 'h', 'e', 'l', 'l', 'o'}, 5, "hello"},
+		{
+			// "abc", then a 9-byte match at offset 3 overlapping itself
+			"overlapping match", []byte{0x35, 'a', 'b', 'c', 3, 0, 0x00}, 12, "abcabcabcabc",
+		},
+		{
+			// 1 literal, then a 4+15+3 = 22 byte run at offset 1
+			"long match", []byte{0x1f, 'z', 1, 0, 3, 0x10, '!'}, 24, strings.Repeat("z", 23) + "!",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeLZ4Block(tt.block, tt.size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("decoded %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range [][]byte{
+		{0x50, 'h', 'i'},             // Literals past the end
+		{0x10, 'a', 9, 0},            // Offset before the start
+		{0x10, 'a', 0, 0},            // Zero offset
+		{0xf0},                       // Missing length byte
+		{0x50, 'h', 'e', 'l', 'l'},   // Truncated
+		{0x10, 'a', 1, 0, 0x00, 'x'}, // Decodes past size
+	} {
+		if _, err := decodeLZ4Block(bad, 5); err == nil {
+			t.Errorf("decodeLZ4Block(%v) succeeded", bad)
+		}
+	}
+}
+
+func TestDecodeMozLz4(t *testing.T) {
+	data := []byte(strings.Repeat("bookmarks ", 100))
+	got, err := decodeMozLz4(mozLz4(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("round trip lost data")
+	}
+
+	if _, err := decodeMozLz4([]byte("{}")); err == nil {
+		t.Error("plain JSON decoded as mozLz4")
+	}
+}
+
+// writeBackups creates backups in profile/bookmarkbackups for the given
+// dates, each holding backupJSON with its title set to the date.
+func writeBackups(t *testing.T, profile string, dates ...string) {
+	t.Helper()
+	dir := filepath.Join(profile, "bookmarkbackups")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, date := range dates {
+		data := strings.Replace(backupJSON, `"title":"Go docs"`, `"title":"Go docs `+date+`"`, 1)
+		name := "bookmarks-" + date + "_4_Xp3b1HQhzBRDzDB9UMkS4A==.jsonlz4"
+		writeFile(t, filepath.Join(dir, name), string(mozLz4([]byte(data))))
+	}
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not a backup")
+}
+
+func TestAdapter_ReadBackup(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "x1.default-release")
+	writeBackups(t, profile, "2026-01-10", "2026-03-01", "2026-02-01")
+
+	a := New("firefox")
+	err := a.Configure(input.Config{
+		Enabled:    true,
+		CustomPath: filepath.Join(profile, "places.sqlite"),
+		Options:    map[string]interface{}{"backup_date": "2026-01-20"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Available() {
+		t.Fatal("expected backups to be available")
+	}
+	if !strings.Contains(a.Path(), "bookmarks-2026-01-10_") {
+		t.Errorf("Path = %s, want the 2026-01-10 backup", a.Path())
+	}
+
+	bookmarks, err := a.Read(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bookmarks) != 2 {
+		t.Fatalf("got %d bookmarks, want 2: %+v", len(bookmarks), bookmarks)
+	}
+
+	b := bookmarks[0]
+	if b.Title != "Go docs 2026-01-10" || b.URL != "https://pkg.go.dev/" {
+		t.Errorf("unexpected bookmark: %+v", b)
+	}
+	if strings.Join(b.FolderPath, "/") != "menu/Dev" {
+		t.Errorf("FolderPath = %v, want [menu Dev]", b.FolderPath)
+	}
+	if strings.Join(b.Tags, ",") != "go,docs" || b.Keyword != "gd" || b.Description != "Package docs" || b.GUID != "GoDocsGuid01" {
+		t.Errorf("Tags/Keyword/Description/GUID = %v/%q/%q/%q", b.Tags, b.Keyword, b.Description, b.GUID)
+	}
+	if !b.DateAdded.Equal(time.UnixMicro(1700000000000000)) {
+		t.Errorf("DateAdded = %v", b.DateAdded)
+	}
+	if b.Source != "firefox" || b.Profile != "x1.default-release" {
+		t.Errorf("Source/Profile = %s/%s", b.Source, b.Profile)
+	}
+
+	if m := bookmarks[1]; m.Title != "https://www.mozilla.org/" || strings.Join(m.FolderPath, "/") != "toolbar" {
+		t.Errorf("unexpected bookmark: %+v", m)
+	}
+}
+
+func TestAdapter_FallsBackToNewestBackup(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "x1.default-release")
+	writeBackups(t, profile, "2026-01-10", "2026-03-01")
+	// A corrupt places.sqlite
+	writeFile(t, filepath.Join(profile, "places.sqlite"), "not a database")
+
+	a := New("firefox")
+	if err := a.Configure(input.Config{Enabled: true, CustomPath: filepath.Join(profile, "places.sqlite")}); err != nil {
+		t.Fatal(err)
+	}
+	bookmarks, err := a.Read(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bookmarks) == 0 || bookmarks[0].Title != "Go docs 2026-03-01" {
+		t.Errorf("expected the newest backup, got %+v", bookmarks)
+	}
+}
+
+func TestClosestBackup(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation("2006-01-02", s, time.Local)
+		return d
+	}
+	backups := []backup{
+		{path: "a", date: day("2026-01-01")},
+		{path: "b", date: day("2026-01-05")},
+		{path: "c", date: day("2026-02-01")},
+	}
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"", "c"},
+		{"2025-06-01", "a"},
+		{"2026-01-03", "a"}, // Equally near a and b
+		{"2026-01-04", "b"},
+		{"2026-03-01", "c"},
+	}
+	for _, tt := range tests {
+		var date time.Time
+		if tt.date != "" {
+			date = day(tt.date)
+		}
+		if got, _ := closestBackup(backups, date); got.path != tt.want {
+			t.Errorf("closestBackup(%s) = %s, want %s", tt.date, got.path, tt.want)
+		}
+	}
+}
@@ -12,198 +12,407 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package firefox provides an input adapter for Firefox.
+// Package firefox provides input adapters for Firefox and Firefox-based
+// browsers.
 package firefox
 
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+
 	"github.com/cloudygreybeard/favs/pkg/adapter"
 	"github.com/cloudygreybeard/favs/pkg/bookmark"
+	"github.com/cloudygreybeard/favs/pkg/diag"
 	"github.com/cloudygreybeard/favs/pkg/input"
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// firefoxPaths maps platform to Firefox profiles directory.
-var firefoxPaths = map[string]string{
-	"linux":   ".mozilla/firefox",
-	"darwin":  "Library/Application Support/Firefox/Profiles",
-	"windows": "Mozilla/Firefox/Profiles",
+// firefoxRoots maps Firefox and its forks to the directories holding
+// their profiles.ini, per platform. Paths are relative to the home
+// directory, or to %APPDATA% on Windows; a leading "~/" is always
+// relative to the home directory.
+var firefoxRoots = map[string]map[string][]string{
+	"firefox": {
+		"linux": {
+			".mozilla/firefox",
+			"snap/firefox/common/.mozilla/firefox",
+			".var/app/org.mozilla.firefox/.mozilla/firefox",
+		},
+		"darwin":  {"Library/Application Support/Firefox"},
+		"windows": {"Mozilla/Firefox"},
+	},
+	"librewolf": {
+		"linux":   {".librewolf", ".var/app/io.gitlab.librewolf-community/.librewolf"},
+		"darwin":  {"Library/Application Support/librewolf"},
+		"windows": {"librewolf"},
+	},
+	"waterfox": {
+		"linux":   {".waterfox", ".var/app/net.waterfox.waterfox/.waterfox"},
+		"darwin":  {"Library/Application Support/Waterfox"},
+		"windows": {"Waterfox"},
+	},
+	"floorp": {
+		"linux":   {".floorp", ".var/app/one.ablaze.floorp/.floorp"},
+		"darwin":  {"Library/Application Support/Floorp"},
+		"windows": {"Floorp"},
+	},
+	"zen": {
+		"linux":   {".zen", ".var/app/app.zen_browser.zen/.zen"},
+		"darwin":  {"Library/Application Support/zen"},
+		"windows": {"zen"},
+	},
+	"tor": {
+		"linux": {
+			".local/share/torbrowser/tbb/x86_64/tor-browser/Browser/TorBrowser/Data/Browser",
+			".var/app/org.torproject.torbrowser-launcher/data/torbrowser/tbb/x86_64/tor-browser/Browser/TorBrowser/Data/Browser",
+		},
+		"darwin":  {"Library/Application Support/TorBrowser-Data/Browser"},
+		"windows": {"~/Desktop/Tor Browser/Browser/TorBrowser/Data/Browser"},
+	},
+}
+
+var displayNames = map[string]string{
+	"firefox":   "Mozilla Firefox",
+	"librewolf": "LibreWolf",
+	"waterfox":  "Waterfox",
+	"floorp":    "Floorp",
+	"zen":       "Zen Browser",
+	"tor":       "Tor Browser",
 }
 
 func init() {
-	adapter.RegisterInput(New())
+	// Register Firefox and its forks
+	for _, browser := range []string{"firefox", "librewolf", "waterfox", "floorp", "zen", "tor"} {
+		adapter.RegisterInputFactory(func() input.Adapter { return New(browser) })
+	}
 }
 
-// Adapter implements input.Adapter for Firefox.
+// Adapter implements input.Adapter for Firefox and Firefox-based browsers.
 type Adapter struct {
-	config  input.Config
-	path    string
-	profile string
+	browser    string
+	config     input.Config
+	path       string
+	profile    string
+	backup     bool      // Read a bookmark backup instead of places.sqlite
+	backupDate time.Time // Backup to read, the newest if zero
 }
 
-// New creates a new Firefox adapter.
-func New() *Adapter {
-	a := &Adapter{}
+// New creates a new adapter for Firefox or one of its forks.
+func New(browser string) *Adapter {
+	a := &Adapter{browser: browser}
 	a.path, a.profile = a.findDatabase()
 	return a
 }
 
 // Name returns the adapter identifier.
 func (a *Adapter) Name() string {
-	return "firefox"
+	return a.browser
 }
 
 // DisplayName returns a human-friendly name.
 func (a *Adapter) DisplayName() string {
-	return "Mozilla Firefox"
+	if name, ok := displayNames[a.browser]; ok {
+		return name
+	}
+	return cases.Title(language.English).String(a.browser)
 }
 
-// Available returns true if Firefox bookmarks are accessible.
+// Available returns true if bookmarks are accessible, from places.sqlite
+// or a bookmark backup.
 func (a *Adapter) Available() bool {
 	if a.path == "" {
 		return false
 	}
+	if a.backup {
+		return a.backupFile() != ""
+	}
 	_, err := os.Stat(a.path)
-	return err == nil
+	return err == nil || a.backupFile() != ""
 }
 
-// Configure applies configuration to the adapter.
+// Configure applies configuration to the adapter. The options are:
+//
+//	backup: true               read the newest bookmark backup
+//	backup_date: 2026-03-01    read the backup closest to a date
+//
+// A custom path naming a .jsonlz4 or .json backup, or a directory of
+// them, also reads backups.
 func (a *Adapter) Configure(cfg input.Config) error {
 	a.config = cfg
 	a.path, a.profile = a.findDatabase()
+
+	a.backup, _ = cfg.Options["backup"].(bool)
+	a.backupDate = time.Time{}
+	switch date := cfg.Options["backup_date"].(type) {
+	case nil:
+	case time.Time:
+		// YAML decodes unquoted dates as midnight UTC
+		a.backupDate = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+	case string:
+		t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			return fmt.Errorf("backup_date: %w", err)
+		}
+		a.backupDate = t
+	default:
+		return fmt.Errorf("backup_date: want a date such as 2026-03-01, got %v", date)
+	}
+	if !a.backupDate.IsZero() || isBackupFile(a.path) {
+		a.backup = true
+	}
+	if info, err := os.Stat(a.path); err == nil && info.IsDir() {
+		a.backup = true
+	}
 	return nil
 }
 
-// Path returns the database path.
+// Path returns the database path, or the backup being read.
 func (a *Adapter) Path() string {
+	if a.backup {
+		if path := a.backupFile(); path != "" {
+			return path
+		}
+	}
 	return a.path
 }
 
-// ListProfiles returns available Firefox profiles.
-func (a *Adapter) ListProfiles() ([]input.ProfileInfo, error) {
-	profilesDir := a.profilesDir()
-	if profilesDir == "" {
-		return nil, nil
+// WatchPaths returns the database and its write-ahead log, or the
+// backups directory.
+func (a *Adapter) WatchPaths() []string {
+	if a.path == "" {
+		return nil
 	}
-
-	entries, err := os.ReadDir(profilesDir)
-	if err != nil {
-		return nil, nil
+	if a.backup {
+		return []string{a.backupDir()}
 	}
+	return []string{a.path, a.path + "-wal"}
+}
 
+// ListProfiles returns the profiles holding bookmarks.
+func (a *Adapter) ListProfiles() ([]input.ProfileInfo, error) {
 	var profiles []input.ProfileInfo
-	for _, entry := range entries {
-		if entry.IsDir() {
-			placesPath := filepath.Join(profilesDir, entry.Name(), "places.sqlite")
-			if _, err := os.Stat(placesPath); err == nil {
-				profiles = append(profiles, input.ProfileInfo{
-					Name:      entry.Name(),
-					Path:      placesPath,
-					IsDefault: entry.Name() == a.profile,
-				})
-			}
-		}
+	for _, p := range a.discoverProfiles() {
+		placesPath := filepath.Join(p.path, "places.sqlite")
+		profiles = append(profiles, input.ProfileInfo{
+			Name:      p.name,
+			Path:      placesPath,
+			IsDefault: p.isDefault,
+		})
 	}
-
 	return profiles, nil
 }
 
-// Read returns all bookmarks from Firefox.
+// Read returns all bookmarks from the selected profile. If places.sqlite
+// cannot be read, because it is locked, corrupt or missing, the newest
+// bookmark backup is read instead.
 func (a *Adapter) Read(ctx context.Context) ([]bookmark.Bookmark, error) {
 	if a.path == "" {
 		return nil, nil
 	}
 
-	// Firefox locks the database, so copy it first
-	tmpFile, err := os.CreateTemp("", "firefox-places-*.sqlite")
-	if err != nil {
+	if a.backup {
+		path := a.backupFile()
+		if path == "" {
+			return nil, fmt.Errorf("no bookmark backups in %s", a.backupDir())
+		}
+		return a.readBackup(path)
+	}
+
+	bookmarks, err := a.readPlaces(ctx)
+	if err == nil {
+		return bookmarks, nil
+	}
+	path := a.backupFile()
+	if path == "" {
 		return nil, err
 	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
+	diag.Report(ctx, diag.Diagnostic{
+		Severity: diag.Warning,
+		Profile:  a.profile,
+		Message:  fmt.Sprintf("reading places.sqlite: %v; reading backup %s instead", err, filepath.Base(path)),
+	})
+	return a.readBackup(path)
+}
+
+// backupDir returns the directory holding the profile's bookmark backups.
+func (a *Adapter) backupDir() string {
+	if info, err := os.Stat(a.path); err == nil && info.IsDir() {
+		return a.path
+	}
+	if isBackupFile(a.path) {
+		return filepath.Dir(a.path)
+	}
+	return filepath.Join(filepath.Dir(a.path), "bookmarkbackups")
+}
 
-	srcFile, err := os.Open(a.path)
+// backupFile returns the backup to read: the custom path if it names one,
+// or else the backup closest to the configured date, or the newest. It
+// returns "" if there are none.
+func (a *Adapter) backupFile() string {
+	if isBackupFile(a.path) {
+		if _, err := os.Stat(a.path); err != nil {
+			return ""
+		}
+		return a.path
+	}
+	b, ok := closestBackup(listBackups(a.backupDir()), a.backupDate)
+	if !ok {
+		return ""
+	}
+	return b.path
+}
+
+// readPlaces reads a snapshot of places.sqlite.
+func (a *Adapter) readPlaces(ctx context.Context) ([]bookmark.Bookmark, error) {
+	// Firefox keeps places.sqlite open in WAL mode, so recent changes may
+	// only exist in the -wal file. Copy the database together with its
+	// -wal and -shm siblings to get a consistent snapshot.
+	tmpDir, err := os.MkdirTemp("", "favs-firefox-*")
 	if err != nil {
 		return nil, err
 	}
-	defer srcFile.Close()
+	defer os.RemoveAll(tmpDir)
 
-	if _, err := io.Copy(tmpFile, srcFile); err != nil {
+	tmpPath := filepath.Join(tmpDir, "places.sqlite")
+	if err := copyFile(a.path, tmpPath); err != nil {
 		return nil, err
 	}
-	tmpFile.Close()
+	for _, suffix := range []string{"-wal", "-shm"} {
+		err := copyFile(a.path+suffix, tmpPath+suffix)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
 
-	db, err := sql.Open("sqlite3", tmpFile.Name()+"?mode=ro")
+	db, err := sql.Open("sqlite3", "file:"+tmpPath+"?mode=ro")
 	if err != nil {
 		return nil, err
 	}
 	defer db.Close()
 
-	return a.readFromDB(db)
+	return a.readFromDB(ctx, db)
 }
 
-func (a *Adapter) profilesDir() string {
-	relPath, ok := firefoxPaths[runtime.GOOS]
-	if !ok {
-		return ""
+// copyFile copies src to dst, creating or truncating dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
 	}
+	defer in.Close()
 
-	var base string
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// roots returns the existing profile roots of the browser.
+func (a *Adapter) roots() []string {
+	home, _ := os.UserHomeDir()
+	base := home
 	if runtime.GOOS == "windows" {
 		base = os.Getenv("APPDATA")
-	} else {
-		base, _ = os.UserHomeDir()
 	}
 
-	return filepath.Join(base, relPath)
+	var roots []string
+	for _, rel := range firefoxRoots[a.browser][runtime.GOOS] {
+		root := filepath.Join(base, rel)
+		if rest, ok := strings.CutPrefix(rel, "~/"); ok {
+			root = filepath.Join(home, rest)
+		}
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			roots = append(roots, root)
+		}
+	}
+	return roots
 }
 
-func (a *Adapter) findDatabase() (string, string) {
-	if a.config.CustomPath != "" {
-		profile := filepath.Base(filepath.Dir(a.config.CustomPath))
-		return a.config.CustomPath, profile
+// discoverProfiles returns the profiles holding places.sqlite or
+// bookmark backups, from profiles.ini where there is one.
+func (a *Adapter) discoverProfiles() []profile {
+	var profiles []profile
+	seen := make(map[string]bool)
+	for _, root := range a.roots() {
+		found, err := readProfilesINI(root)
+		if err != nil {
+			found = scanProfiles(root)
+		}
+		for _, p := range found {
+			if seen[p.path] {
+				continue
+			}
+			seen[p.path] = true
+			if hasBookmarks(p.path) {
+				profiles = append(profiles, p)
+			}
+		}
 	}
+	return profiles
+}
 
-	profilesDir := a.profilesDir()
-	if profilesDir == "" {
-		return "", ""
+// hasBookmarks reports whether a profile directory holds places.sqlite or
+// bookmark backups.
+func hasBookmarks(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, "places.sqlite")); err == nil {
+		return true
 	}
+	return len(listBackups(filepath.Join(dir, "bookmarkbackups"))) > 0
+}
 
-	if _, err := os.Stat(profilesDir); err != nil {
-		return "", ""
+func (a *Adapter) findDatabase() (string, string) {
+	if a.config.CustomPath != "" {
+		// Name the profile after the directory holding places.sqlite and
+		// bookmarkbackups
+		dir := filepath.Dir(a.config.CustomPath)
+		if filepath.Base(dir) == "bookmarkbackups" {
+			dir = filepath.Dir(dir)
+		}
+		return a.config.CustomPath, filepath.Base(dir)
 	}
 
-	// If profile specified, use it directly
-	if a.config.Profile != "" {
-		return filepath.Join(profilesDir, a.config.Profile, "places.sqlite"), a.config.Profile
+	profiles := a.discoverProfiles()
+	if len(profiles) == 0 {
+		return "", ""
 	}
 
-	// Find first profile with places.sqlite
-	entries, err := os.ReadDir(profilesDir)
-	if err != nil {
+	for _, p := range profiles {
+		if a.config.Profile != "" && p.matches(a.config.Profile) {
+			return filepath.Join(p.path, "places.sqlite"), p.name
+		}
+	}
+	// "Default" is what the CLI asks for when no profile is given
+	if a.config.Profile != "" && a.config.Profile != "Default" {
 		return "", ""
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			placesPath := filepath.Join(profilesDir, entry.Name(), "places.sqlite")
-			if _, err := os.Stat(placesPath); err == nil {
-				return placesPath, entry.Name()
-			}
+	selected := profiles[0]
+	for _, p := range profiles {
+		if p.isDefault {
+			selected = p
+			break
 		}
 	}
 
-	return "", ""
+	return filepath.Join(selected.path, "places.sqlite"), selected.name
 }
 
-func (a *Adapter) readFromDB(db *sql.DB) ([]bookmark.Bookmark, error) {
+func (a *Adapter) readFromDB(ctx context.Context, db *sql.DB) ([]bookmark.Bookmark, error) {
 	// Build folder hierarchy and identify tag folders
 	folders := make(map[int64]struct {
 		Parent int64
@@ -249,6 +458,12 @@ func (a *Adapter) readFromDB(db *sql.DB) ([]bookmark.Bookmark, error) {
 				tagsByURL[url] = append(tagsByURL[url], tag)
 			}
 		}
+	} else {
+		diag.Report(ctx, diag.Diagnostic{
+			Severity: diag.Warning,
+			Profile:  a.profile,
+			Message:  fmt.Sprintf("reading tags: %v", err),
+		})
 	}
 
 	// Get bookmarks
@@ -276,6 +491,11 @@ func (a *Adapter) readFromDB(db *sql.DB) ([]bookmark.Bookmark, error) {
 		var dateAdded sql.NullInt64
 
 		if err := rows.Scan(&id, &title, &url, &parentID, &dateAdded); err != nil {
+			diag.Report(ctx, diag.Diagnostic{
+				Severity: diag.Error,
+				Profile:  a.profile,
+				Message:  fmt.Sprintf("skipping bookmark: %v", err),
+			})
 			continue
 		}
 
@@ -317,7 +537,7 @@ func (a *Adapter) readFromDB(db *sql.DB) ([]bookmark.Bookmark, error) {
 			URL:        url,
 			FolderPath: folderPath,
 			DateAdded:  addedTime,
-			Source:     "firefox",
+			Source:     a.browser,
 			Profile:    a.profile,
 			Tags:       tagsByURL[url],
 		})
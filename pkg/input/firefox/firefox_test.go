@@ -0,0 +1,308 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firefox
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cloudygreybeard/favs/pkg/input"
+)
+
+// placesSchema is the subset of the Firefox places schema read by the adapter.
+const placesSchema = `
+CREATE TABLE moz_places (id INTEGER PRIMARY KEY, url LONGVARCHAR);
+CREATE TABLE moz_bookmarks (
+	id INTEGER PRIMARY KEY,
+	type INTEGER,
+	fk INTEGER DEFAULT NULL,
+	parent INTEGER,
+	title LONGVARCHAR,
+	dateAdded INTEGER
+);
+INSERT INTO moz_bookmarks (id, type, parent, title) VALUES (1, 2, 0, '');
+INSERT INTO moz_bookmarks (id, type, parent, title) VALUES (3, 2, 1, 'toolbar');
+INSERT INTO moz_bookmarks (id, type, parent, title) VALUES (4, 2, 1, 'tags');
+`
+
+// openPlaces creates a places.sqlite in WAL mode with automatic
+// checkpointing disabled, so writes stay in the -wal file until the
+// returned connection is closed.
+func openPlaces(t *testing.T, path string) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?_journal_mode=WAL")
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec("PRAGMA wal_autocheckpoint = 0"); err != nil {
+		t.Fatalf("disabling checkpoints: %v", err)
+	}
+	if _, err := db.Exec(placesSchema); err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		t.Fatalf("checkpointing schema: %v", err)
+	}
+	return db
+}
+
+func addBookmark(t *testing.T, db *sql.DB, id int64, title, url string) {
+	t.Helper()
+	if _, err := db.Exec("INSERT INTO moz_places (id, url) VALUES (?, ?)", id, url); err != nil {
+		t.Fatalf("inserting place: %v", err)
+	}
+	if _, err := db.Exec(
+		"INSERT INTO moz_bookmarks (id, type, fk, parent, title, dateAdded) VALUES (?, 1, ?, 3, ?, 1700000000000000)",
+		100+id, id, title,
+	); err != nil {
+		t.Fatalf("inserting bookmark: %v", err)
+	}
+}
+
+func TestAdapter_ReadIncludesWAL(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "places.sqlite")
+
+	db := openPlaces(t, path)
+	addBookmark(t, db, 1, "Recent", "https://example.com/recent")
+
+	info, err := os.Stat(path + "-wal")
+	if err != nil || info.Size() == 0 {
+		t.Fatalf("expected uncheckpointed WAL data, stat err=%v", err)
+	}
+
+	a := New("firefox")
+	if err := a.Configure(input.Config{Enabled: true, CustomPath: path}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+
+	bookmarks, err := a.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	if len(bookmarks) != 1 {
+		t.Fatalf("got %d bookmarks, want 1", len(bookmarks))
+	}
+	b := bookmarks[0]
+	if b.URL != "https://example.com/recent" || b.Title != "Recent" {
+		t.Errorf("unexpected bookmark: %+v", b)
+	}
+	if len(b.FolderPath) != 1 || b.FolderPath[0] != "toolbar" {
+		t.Errorf("FolderPath = %v, want [toolbar]", b.FolderPath)
+	}
+}
+
+// fakeRoot points the home directory at a temporary one and returns the
+// first profile root of browser within it.
+func fakeRoot(t *testing.T, browser string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("APPDATA", home)
+
+	rels := firefoxRoots[browser][runtime.GOOS]
+	if len(rels) == 0 {
+		t.Skipf("%s has no profile root on %s", browser, runtime.GOOS)
+	}
+	root := filepath.Join(home, strings.TrimPrefix(rels[0], "~/"))
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdapter_ForkProfiles(t *testing.T) {
+	root := fakeRoot(t, "librewolf")
+	dirs := []string{filepath.Join(root, "a1.default"), filepath.Join(root, "Profiles", "b2.default-default")}
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	openPlaces(t, filepath.Join(dirs[0], "places.sqlite"))
+	db := openPlaces(t, filepath.Join(dirs[1], "places.sqlite"))
+	addBookmark(t, db, 1, "Wolf", "https://librewolf.net/")
+
+	a := New("librewolf")
+	if a.Name() != "librewolf" || a.DisplayName() != "LibreWolf" {
+		t.Errorf("Name/DisplayName = %q/%q", a.Name(), a.DisplayName())
+	}
+	if !a.Available() || a.profile != "a1.default" {
+		t.Errorf("Available = %v, profile = %q", a.Available(), a.profile)
+	}
+
+	profiles, err := a.ListProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 || profiles[1].Name != "b2.default-default" {
+		t.Errorf("unexpected profiles: %+v", profiles)
+	}
+
+	if err := a.Configure(input.Config{Enabled: true, Profile: "b2.default-default"}); err != nil {
+		t.Fatal(err)
+	}
+	bookmarks, err := a.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(bookmarks) != 1 || bookmarks[0].Source != "librewolf" || bookmarks[0].Profile != "b2.default-default" {
+		t.Errorf("unexpected bookmarks: %+v", bookmarks)
+	}
+
+	if err := a.Configure(input.Config{Enabled: true, Profile: "missing"}); err != nil {
+		t.Fatal(err)
+	}
+	if a.Available() {
+		t.Error("a missing profile is available")
+	}
+}
+
+func TestAdapter_DefaultProfileFromInstallsINI(t *testing.T) {
+	root := fakeRoot(t, "librewolf")
+	elsewhere := t.TempDir()
+	dirs := map[string]string{
+		"a1.default":         filepath.Join(root, "a1.default"),
+		"b2.default-default": filepath.Join(root, "b2.default-default"),
+		"c3.nightly":         filepath.Join(elsewhere, "c3.nightly"),
+	}
+	for name, dir := range dirs {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		db := openPlaces(t, filepath.Join(dir, "places.sqlite"))
+		if name == "b2.default-default" {
+			addBookmark(t, db, 1, "Wolf", "https://librewolf.net/")
+		}
+	}
+
+	// profiles.ini still marks the legacy profile; the installs win. The
+	// nightly profile is the default of a second install, at an absolute
+	// path.
+	writeFile(t, filepath.Join(root, "profiles.ini"), `[General]
+StartWithLastProfile=1
+
+[Profile2]
+Name=nightly
+IsRelative=0
+Path=`+filepath.ToSlash(dirs["c3.nightly"])+`
+
+[Profile1]
+Name=default
+IsRelative=1
+Path=a1.default
+Default=1
+
+[Profile0]
+Name=default-default
+IsRelative=1
+Path=b2.default-default
+
+[Install9F1E2D3C4B5A6978]
+Default=`+filepath.ToSlash(dirs["c3.nightly"])+`
+Locked=1
+`)
+	writeFile(t, filepath.Join(root, "installs.ini"), `[6C3A2B1F0E9D8C7B]
+Default=b2.default-default
+Locked=1
+`)
+
+	a := New("librewolf")
+	if a.Name() != "librewolf" || a.DisplayName() != "LibreWolf" {
+		t.Errorf("Name/DisplayName = %q/%q", a.Name(), a.DisplayName())
+	}
+	if !a.Available() {
+		t.Fatal("expected adapter to be available")
+	}
+
+	profiles, err := a.ListProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range profiles {
+		if p.IsDefault {
+			got = append(got, p.Name+" (default)")
+		} else {
+			got = append(got, p.Name)
+		}
+	}
+	if want := "nightly (default),default,default-default (default)"; strings.Join(got, ",") != want {
+		t.Errorf("profiles = %s, want %s", strings.Join(got, ","), want)
+	}
+
+	tests := []struct {
+		profile string // -p value
+		want    string // Profile the bookmarks carry
+	}{
+		{"", "nightly"},
+		{"Default", "nightly"},
+		{"default", "default"},
+		{"b2.default-default", "default-default"},
+		{"c3.nightly", "nightly"},
+		{"missing", ""},
+	}
+	for _, tt := range tests {
+		if err := a.Configure(input.Config{Enabled: true, Profile: tt.profile}); err != nil {
+			t.Fatal(err)
+		}
+		if a.profile != tt.want {
+			t.Errorf("-p %q: profile = %q, want %q", tt.profile, a.profile, tt.want)
+		}
+	}
+
+	if err := a.Configure(input.Config{Enabled: true, Profile: "default-default"}); err != nil {
+		t.Fatal(err)
+	}
+	bookmarks, err := a.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(bookmarks) != 1 || bookmarks[0].Source != "librewolf" || bookmarks[0].Profile != "default-default" {
+		t.Errorf("unexpected bookmarks: %+v", bookmarks)
+	}
+}
+
+func TestAdapter_ScansRootWithoutProfilesINI(t *testing.T) {
+	root := fakeRoot(t, "tor")
+	dir := filepath.Join(root, "profile.default")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	openPlaces(t, filepath.Join(dir, "places.sqlite"))
+
+	a := New("tor")
+	if !a.Available() || a.profile != "profile.default" {
+		t.Errorf("Available = %v, profile = %q", a.Available(), a.profile)
+	}
+}
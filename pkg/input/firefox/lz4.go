@@ -0,0 +1,119 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firefox
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// mozLz4Magic starts Mozilla's LZ4 files (.jsonlz4, .mozlz4).
+var mozLz4Magic = []byte("mozLz40\x00")
+
+// maxDecodedSize bounds the size a mozLz4 header may claim.
+const maxDecodedSize = 1 << 30
+
+var errCorrupt = errors.New("corrupt LZ4 block")
+
+// decodeMozLz4 decodes a mozLz4 file: the magic, the decoded size as a
+// little-endian uint32 and a single LZ4 block.
+func decodeMozLz4(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, mozLz4Magic) || len(data) < len(mozLz4Magic)+4 {
+		return nil, fmt.Errorf("not a mozLz4 file")
+	}
+	size := binary.LittleEndian.Uint32(data[len(mozLz4Magic):])
+	if size > maxDecodedSize {
+		return nil, fmt.Errorf("mozLz4 file claims %d bytes", size)
+	}
+	return decodeLZ4Block(data[len(mozLz4Magic)+4:], int(size))
+}
+
+// decodeLZ4Block decodes an LZ4 block, which must decode to exactly size
+// bytes. Each sequence is a token, literals and a match copied from
+// earlier output; the last sequence has literals only.
+func decodeLZ4Block(src []byte, size int) ([]byte, error) {
+	dst := make([]byte, 0, size)
+	i := 0
+	for i < len(src) {
+		token := src[i]
+		i++
+
+		literals, n, err := lz4Length(src[i:], int(token>>4))
+		if err != nil {
+			return nil, err
+		}
+		i += n
+		if literals > len(src)-i || literals > size-len(dst) {
+			return nil, errCorrupt
+		}
+		dst = append(dst, src[i:i+literals]...)
+		i += literals
+		if i == len(src) {
+			break
+		}
+
+		if len(src)-i < 2 {
+			return nil, errCorrupt
+		}
+		offset := int(binary.LittleEndian.Uint16(src[i:]))
+		i += 2
+		if offset == 0 || offset > len(dst) {
+			return nil, errCorrupt
+		}
+
+		match, n, err := lz4Length(src[i:], int(token&0x0f))
+		if err != nil {
+			return nil, err
+		}
+		i += n
+		match += 4
+		if match > size-len(dst) {
+			return nil, errCorrupt
+		}
+		// Byte by byte, as the match may overlap what it copies
+		start := len(dst) - offset
+		for k := 0; k < match; k++ {
+			dst = append(dst, dst[start+k])
+		}
+	}
+
+	if len(dst) != size {
+		return nil, fmt.Errorf("LZ4 block decoded to %d bytes, want %d", len(dst), size)
+	}
+	return dst, nil
+}
+
+// lz4Length completes a length from a token nibble: a nibble of 15 is
+// followed by bytes that are added to it up to one below 255. It returns
+// the length and the bytes consumed.
+func lz4Length(src []byte, nibble int) (int, int, error) {
+	if nibble != 15 {
+		return nibble, 0, nil
+	}
+	length, n := nibble, 0
+	for {
+		if n >= len(src) || length > maxDecodedSize {
+			return 0, 0, errCorrupt
+		}
+		b := src[n]
+		n++
+		length += int(b)
+		if b != 255 {
+			return length, n, nil
+		}
+	}
+}
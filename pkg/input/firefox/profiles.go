@@ -0,0 +1,156 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firefox
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// profile is a Firefox profile directory.
+type profile struct {
+	name      string // Name from profiles.ini, or else the directory name
+	path      string // Profile directory
+	isDefault bool
+}
+
+// matches reports whether a -p value names the profile, by its name or
+// its directory name.
+func (p profile) matches(name string) bool {
+	return p.name == name || filepath.Base(p.path) == name
+}
+
+// iniSection is a section of an INI file.
+type iniSection struct {
+	name string
+	keys map[string]string
+}
+
+// readINI reads the sections of an INI file, in order.
+func readINI(path string) ([]iniSection, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var sections []iniSection
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == ';' || line[0] == '#':
+		case line[0] == '[' && line[len(line)-1] == ']':
+			sections = append(sections, iniSection{
+				name: line[1 : len(line)-1],
+				keys: make(map[string]string),
+			})
+		case len(sections) > 0:
+			if key, value, ok := strings.Cut(line, "="); ok {
+				sections[len(sections)-1].keys[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return sections, scanner.Err()
+}
+
+// readProfilesINI returns the profiles listed in root/profiles.ini. Each
+// install (release, beta, nightly, ...) has its default profile, named by
+// installs.ini or an [Install…] section since Firefox 67; all of them are
+// marked as defaults. Without installs, the default is the profile marked
+// Default=1.
+func readProfilesINI(root string) ([]profile, error) {
+	sections, err := readINI(filepath.Join(root, "profiles.ini"))
+	if err != nil {
+		return nil, err
+	}
+
+	var installDefaults []string
+	if installs, err := readINI(filepath.Join(root, "installs.ini")); err == nil {
+		for _, s := range installs {
+			if d := s.keys["Default"]; d != "" {
+				installDefaults = append(installDefaults, profilePath(root, d, true))
+			}
+		}
+	}
+
+	var profiles []profile
+	marked := -1
+	for _, s := range sections {
+		switch {
+		case strings.HasPrefix(s.name, "Install"):
+			if d := s.keys["Default"]; d != "" {
+				installDefaults = append(installDefaults, profilePath(root, d, true))
+			}
+		case strings.HasPrefix(s.name, "Profile"):
+			p := s.keys["Path"]
+			if p == "" {
+				continue
+			}
+			path := profilePath(root, p, s.keys["IsRelative"] != "0")
+			name := s.keys["Name"]
+			if name == "" {
+				name = filepath.Base(path)
+			}
+			profiles = append(profiles, profile{name: name, path: path})
+			if s.keys["Default"] == "1" {
+				marked = len(profiles) - 1
+			}
+		}
+	}
+
+	if len(installDefaults) > 0 {
+		for i, p := range profiles {
+			profiles[i].isDefault = slices.Contains(installDefaults, p.path)
+		}
+	} else if marked >= 0 {
+		profiles[marked].isDefault = true
+	}
+	return profiles, nil
+}
+
+// profilePath resolves a Path or Default entry, which uses forward
+// slashes and is relative to root unless relative is false.
+func profilePath(root, p string, relative bool) string {
+	p = filepath.FromSlash(p)
+	if relative && !filepath.IsAbs(p) {
+		return filepath.Join(root, p)
+	}
+	return filepath.Clean(p)
+}
+
+// scanProfiles lists the directories of root and root/Profiles, for
+// installs without a profiles.ini such as portable builds.
+func scanProfiles(root string) []profile {
+	var profiles []profile
+	for _, dir := range []string{root, filepath.Join(root, "Profiles")} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				profiles = append(profiles, profile{
+					name: entry.Name(),
+					path: filepath.Join(dir, entry.Name()),
+				})
+			}
+		}
+	}
+	return profiles
+}
@@ -24,7 +24,7 @@
 //
 //  1. Create a new package under pkg/input/
 //  2. Implement the Adapter interface
-//  3. Register via init() using adapter.RegisterInput()
+//  3. Register via init() using adapter.RegisterInputFactory()
 //  4. Import in cmd/root.go to include in the build
 //
 // Example:
@@ -39,7 +39,7 @@
 //	)
 //
 //	func init() {
-//	    adapter.RegisterInput(New())
+//	    adapter.RegisterInputFactory(func() input.Adapter { return New() })
 //	}
 //
 //	type Adapter struct {
@@ -119,6 +119,15 @@ type Adapter interface {
 	Read(ctx context.Context) ([]bookmark.Bookmark, error)
 }
 
+// Watchable is implemented by adapters backed by local files.
+// Long-running consumers such as the MCP server poll these files and
+// re-read the adapter when they change.
+type Watchable interface {
+	// WatchPaths returns the files whose changes indicate new or
+	// modified bookmarks. Paths that do not exist yet may be included.
+	WatchPaths() []string
+}
+
 // Config holds adapter-specific configuration passed at runtime.
 type Config struct {
 	// Enabled indicates whether this adapter should be used.
@@ -143,6 +152,10 @@ type ProfileInfo struct {
 	// Name is the profile identifier (e.g., "Default", "Profile 1").
 	Name string
 
+	// DisplayName is the name the user gave the profile (e.g., "Work"),
+	// if the browser keeps one apart from Name.
+	DisplayName string
+
 	// Path is the filesystem path or URI for this profile.
 	Path string
 
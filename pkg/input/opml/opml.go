@@ -30,7 +30,7 @@ import (
 )
 
 func init() {
-	adapter.RegisterInput(&Adapter{})
+	adapter.RegisterInputFactory(func() input.Adapter { return &Adapter{} })
 }
 
 // Adapter reads bookmarks from OPML or Netscape HTML files.
@@ -50,6 +50,14 @@ func (a *Adapter) Available() bool { return a.path != "" }
 // Path returns the configured file path.
 func (a *Adapter) Path() string { return a.path }
 
+// WatchPaths returns the configured file.
+func (a *Adapter) WatchPaths() []string {
+	if a.path == "" {
+		return nil
+	}
+	return []string{a.path}
+}
+
 // Configure sets up the adapter with the given configuration.
 func (a *Adapter) Configure(cfg input.Config) error {
 	a.path = cfg.CustomPath
@@ -28,7 +28,7 @@ import (
 )
 
 func init() {
-	adapter.RegisterInput(New())
+	adapter.RegisterInputFactory(func() input.Adapter { return New() })
 }
 
 // Adapter implements input.Adapter for Safari.
@@ -78,6 +78,14 @@ func (a *Adapter) Path() string {
 	return a.path
 }
 
+// WatchPaths returns the bookmarks plist.
+func (a *Adapter) WatchPaths() []string {
+	if a.path == "" {
+		return nil
+	}
+	return []string{a.path}
+}
+
 // ListProfiles returns available profiles (Safari has only one).
 func (a *Adapter) ListProfiles() ([]input.ProfileInfo, error) {
 	if !a.Available() {
@@ -0,0 +1,540 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sessionHeader carries the session ID of the Streamable HTTP transport.
+const sessionHeader = "Mcp-Session-Id"
+
+// maxRequestBody limits the size of a POSTed JSON-RPC message.
+const maxRequestBody = 4 << 20
+
+// defaultSessionTimeout is how long an idle session is kept.
+const defaultSessionTimeout = 30 * time.Minute
+
+// HTTPOptions configures the Streamable HTTP transport.
+type HTTPOptions struct {
+	// Addr is the TCP address to listen on (e.g. "127.0.0.1:8080").
+	Addr string
+
+	// Path is the MCP endpoint path. Defaults to "/mcp".
+	Path string
+
+	// Token, if set, must be presented as "Authorization: Bearer <token>"
+	// on every request.
+	Token string
+
+	// SessionTimeout closes sessions that have seen no request for this
+	// long, unless they have an open event stream. Defaults to 30 minutes.
+	SessionTimeout time.Duration
+
+	// AllowedOrigins lists the browser origins (e.g.
+	// "https://app.example.com") accepted besides loopback ones.
+	AllowedOrigins []string
+}
+
+// RunHTTP serves MCP over the Streamable HTTP transport until ctx is
+// cancelled. Several clients can share one server; each gets its own
+// session.
+func (s *Server) RunHTTP(ctx context.Context, opts HTTPOptions) error {
+	srv := &http.Server{
+		Addr:              opts.Addr,
+		Handler:           s.HTTPHandler(opts),
+		ReadHeaderTimeout: 10 * time.Second,
+		// Derive request contexts from ctx so open event streams end
+		// when the server shuts down.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+// HTTPHandler returns an http.Handler implementing the MCP Streamable
+// HTTP transport at opts.Path.
+func (s *Server) HTTPHandler(opts HTTPOptions) http.Handler {
+	path := opts.Path
+	if path == "" {
+		path = "/mcp"
+	}
+
+	timeout := opts.SessionTimeout
+	if timeout <= 0 {
+		timeout = defaultSessionTimeout
+	}
+
+	t := &httpTransport{
+		server:   s,
+		token:    opts.Token,
+		timeout:  timeout,
+		addr:     opts.Addr,
+		origins:  opts.AllowedOrigins,
+		sessions: make(map[string]*httpSession),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, t)
+	return mux
+}
+
+// httpTransport implements the Streamable HTTP transport.
+type httpTransport struct {
+	server  *Server
+	token   string
+	timeout time.Duration
+	addr    string   // Listen address, accepted as a Host header
+	origins []string // Allowed non-loopback origins
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+// httpSession is a session with a queue of server-initiated messages,
+// delivered to the client over a GET event stream.
+type httpSession struct {
+	*session
+	events chan interface{}
+	done   chan struct{}
+	once   sync.Once
+
+	// Guarded by httpTransport.mu
+	lastSeen time.Time
+	streams  int // Open GET event streams
+}
+
+func (hs *httpSession) close() {
+	hs.once.Do(func() { close(hs.done) })
+}
+
+func (t *httpTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !t.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="favs"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !t.validHost(r) {
+		http.Error(w, "forbidden host", http.StatusForbidden)
+		return
+	}
+	if !t.validOrigin(r) {
+		http.Error(w, "forbidden origin", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		t.handlePost(w, r)
+	case http.MethodGet:
+		t.handleGet(w, r)
+	case http.MethodDelete:
+		t.handleDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (t *httpTransport) authorized(r *http.Request) bool {
+	if t.token == "" {
+		return true
+	}
+	auth := r.Header.Get("Authorization")
+	token, ok := strings.CutPrefix(auth, "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(t.token)) == 1
+}
+
+// validHost rejects requests whose Host header names neither a loopback
+// host nor the listen address. After a DNS rebinding attack the browser
+// sends the attacker's host name, so such requests never reach the
+// server.
+func (t *httpTransport) validHost(r *http.Request) bool {
+	if isLoopback(hostname(r.Host)) {
+		return true
+	}
+	return t.addr != "" && strings.EqualFold(r.Host, t.addr)
+}
+
+// validOrigin rejects browser requests from origins other than loopback
+// ones and those allowed by HTTPOptions.AllowedOrigins.
+func (t *httpTransport) validOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if isLoopback(u.Hostname()) {
+		return true
+	}
+	for _, allowed := range t.origins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostname strips the port from a Host header.
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return strings.Trim(host, "[]")
+}
+
+// isLoopback reports whether host is localhost or a loopback address.
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (t *httpTransport) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBody))
+	if err != nil {
+		http.Error(w, "reading request body", http.StatusBadRequest)
+		return
+	}
+
+	reqs, batch, err := decodeMessages(body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(nil, -32700, "Parse error"))
+		return
+	}
+
+	var sess *httpSession
+	if isInitialize(reqs) {
+		if len(reqs) != 1 {
+			writeJSON(w, http.StatusBadRequest, errorResponse(nil, -32600, "initialize must not be batched"))
+			return
+		}
+		sess, err = t.newSession()
+		if err != nil {
+			http.Error(w, "creating session", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set(sessionHeader, sess.id)
+	} else if sess = t.lookup(w, r); sess == nil {
+		return
+	}
+
+	ctx := withSession(r.Context(), sess.session)
+
+	if !hasRequests(reqs) {
+		// Only notifications or responses: acknowledge without a body
+		for _, req := range reqs {
+			t.server.handleRequest(ctx, req)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if acceptsEventStream(r) {
+		t.streamResponses(ctx, w, reqs)
+		return
+	}
+
+	var responses []*Response
+	for _, req := range reqs {
+		if resp := t.server.handleRequest(ctx, req); resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+	if batch {
+		writeJSON(w, http.StatusOK, responses)
+		return
+	}
+	writeJSON(w, http.StatusOK, responses[0])
+}
+
+// streamResponses answers a POST with an event stream carrying any
+// notifications raised while handling the requests, then the responses.
+func (t *httpTransport) streamResponses(ctx context.Context, w http.ResponseWriter, reqs []*Request) {
+	sse, ok := newEventWriter(w)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ctx = withSender(ctx, sse.write)
+	for _, req := range reqs {
+		if resp := t.server.handleRequest(ctx, req); resp != nil {
+			_ = sse.write(resp)
+		}
+	}
+}
+
+func (t *httpTransport) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !acceptsEventStream(r) {
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "GET requires Accept: text/event-stream", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sess := t.lookup(w, r)
+	if sess == nil {
+		return
+	}
+
+	sse, ok := newEventWriter(w)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// A session with an open stream is never idle
+	t.mu.Lock()
+	sess.streams++
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		sess.streams--
+		sess.lastSeen = time.Now()
+		t.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sess.done:
+			return
+		case msg := <-sess.events:
+			if err := sse.write(msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (t *httpTransport) handleDelete(w http.ResponseWriter, r *http.Request) {
+	sess := t.lookup(w, r)
+	if sess == nil {
+		return
+	}
+
+	t.mu.Lock()
+	delete(t.sessions, sess.id)
+	t.mu.Unlock()
+	t.closeSessions([]*httpSession{sess})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (t *httpTransport) newSession() (*httpSession, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+
+	hs := &httpSession{
+		events: make(chan interface{}, 64),
+		done:   make(chan struct{}),
+	}
+	hs.session = newSession(func(msg interface{}) error {
+		select {
+		case hs.events <- msg:
+			return nil
+		case <-hs.done:
+			return errors.New("session closed")
+		default:
+			// No client is draining the event stream; drop the message
+			// rather than block the server.
+			return errors.New("event queue full")
+		}
+	})
+	hs.id = hex.EncodeToString(b[:])
+	hs.lastSeen = time.Now()
+
+	t.mu.Lock()
+	expired := t.expireLocked(hs.lastSeen)
+	t.sessions[hs.id] = hs
+	t.mu.Unlock()
+	t.closeSessions(expired)
+	t.server.addSession(hs.session)
+	return hs, nil
+}
+
+// lookup returns the session named by the request's Mcp-Session-Id
+// header, or writes an error response and returns nil.
+func (t *httpTransport) lookup(w http.ResponseWriter, r *http.Request) *httpSession {
+	id := r.Header.Get(sessionHeader)
+	if id == "" {
+		http.Error(w, "missing "+sessionHeader+" header", http.StatusBadRequest)
+		return nil
+	}
+
+	now := time.Now()
+	t.mu.Lock()
+	expired := t.expireLocked(now)
+	sess, ok := t.sessions[id]
+	if ok {
+		sess.lastSeen = now
+	}
+	t.mu.Unlock()
+	t.closeSessions(expired)
+
+	if !ok {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return nil
+	}
+	return sess
+}
+
+// expireLocked removes the sessions idle for longer than the timeout
+// and returns them for closeSessions. Expiry happens as requests arrive,
+// so no goroutine has to sweep. t.mu must be held.
+func (t *httpTransport) expireLocked(now time.Time) []*httpSession {
+	var expired []*httpSession
+	for id, sess := range t.sessions {
+		if sess.streams == 0 && now.Sub(sess.lastSeen) > t.timeout {
+			delete(t.sessions, id)
+			expired = append(expired, sess)
+		}
+	}
+	return expired
+}
+
+// closeSessions ends sessions already removed from t.sessions.
+func (t *httpTransport) closeSessions(sessions []*httpSession) {
+	for _, sess := range sessions {
+		t.server.removeSession(sess.session)
+		sess.close()
+	}
+}
+
+// decodeMessages parses a single JSON-RPC message or a batch.
+func decodeMessages(body []byte) (reqs []*Request, batch bool, err error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			return nil, true, err
+		}
+		if len(reqs) == 0 {
+			return nil, true, errors.New("empty batch")
+		}
+		return reqs, true, nil
+	}
+
+	var req Request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, false, err
+	}
+	return []*Request{&req}, false, nil
+}
+
+func isInitialize(reqs []*Request) bool {
+	for _, req := range reqs {
+		if req.Method == "initialize" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRequests reports whether any message expects a response.
+// Notifications and client responses carry no method or no ID.
+func hasRequests(reqs []*Request) bool {
+	for _, req := range reqs {
+		if req.ID != nil && req.Method != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// eventWriter writes JSON-RPC messages as server-sent events.
+type eventWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func newEventWriter(w http.ResponseWriter) (*eventWriter, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &eventWriter{w: w, flusher: flusher}, true
+}
+
+func (e *eventWriter) write(msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err := fmt.Fprintf(e.w, "event: message\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+	e.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,250 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudygreybeard/favs/pkg/config"
+)
+
+const initializeBody = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`
+
+func newTestServer(t *testing.T, opts HTTPOptions) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(NewServer(config.Config{}).HTTPHandler(opts))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func post(t *testing.T, ts *httptest.Server, sessionID, accept, body string, header ...string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/mcp", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	if sessionID != "" {
+		req.Header.Set(sessionHeader, sessionID)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func initialize(t *testing.T, ts *httptest.Server) string {
+	t.Helper()
+	resp := post(t, ts, "", "application/json, text/event-stream", initializeBody)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("initialize: status %d", resp.StatusCode)
+	}
+	id := resp.Header.Get(sessionHeader)
+	if id == "" {
+		t.Fatal("initialize: missing session ID")
+	}
+	return id
+}
+
+func TestHTTP_Initialize(t *testing.T) {
+	ts := newTestServer(t, HTTPOptions{})
+
+	resp := post(t, ts, "", "application/json", initializeBody)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if resp.Header.Get(sessionHeader) == "" {
+		t.Error("missing Mcp-Session-Id header")
+	}
+
+	var result struct {
+		Result struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.Result.ProtocolVersion != "2025-03-26" {
+		t.Errorf("protocolVersion = %q", result.Result.ProtocolVersion)
+	}
+}
+
+func TestHTTP_Sessions(t *testing.T) {
+	ts := newTestServer(t, HTTPOptions{})
+	list := `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`
+
+	if resp := post(t, ts, "", "application/json", list); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("without session: status = %d, want 400", resp.StatusCode)
+	}
+	if resp := post(t, ts, "nope", "application/json", list); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown session: status = %d, want 404", resp.StatusCode)
+	}
+
+	id := initialize(t, ts)
+	if resp := post(t, ts, id, "application/json", list); resp.StatusCode != http.StatusOK {
+		t.Errorf("valid session: status = %d, want 200", resp.StatusCode)
+	}
+
+	notification := `{"jsonrpc":"2.0","method":"notifications/initialized"}`
+	if resp := post(t, ts, id, "application/json", notification); resp.StatusCode != http.StatusAccepted {
+		t.Errorf("notification: status = %d, want 202", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/mcp", nil)
+	req.Header.Set(sessionHeader, id)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp := post(t, ts, id, "application/json", list); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("after delete: status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestHTTP_SessionTimeout(t *testing.T) {
+	ts := newTestServer(t, HTTPOptions{SessionTimeout: 100 * time.Millisecond})
+	ping := `{"jsonrpc":"2.0","id":2,"method":"ping"}`
+
+	active, idle := initialize(t, ts), initialize(t, ts)
+	for i := 0; i < 3; i++ {
+		time.Sleep(50 * time.Millisecond)
+		if resp := post(t, ts, active, "application/json", ping); resp.StatusCode != http.StatusOK {
+			t.Fatalf("active session: status = %d, want 200", resp.StatusCode)
+		}
+	}
+	if resp := post(t, ts, idle, "application/json", ping); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("idle session: status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestHTTP_ClientResponsesIgnored(t *testing.T) {
+	ts := newTestServer(t, HTTPOptions{})
+	id := initialize(t, ts)
+
+	// A reply to a server request, batched with a ping
+	batch := `[{"jsonrpc":"2.0","id":"srv-1","result":{}},{"jsonrpc":"2.0","id":4,"method":"ping"}]`
+	resp := post(t, ts, id, "application/json", batch)
+	var responses []Response
+	if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 1 || responses[0].Error != nil || responses[0].ID != float64(4) {
+		t.Errorf("responses = %+v, want only the ping's", responses)
+	}
+
+	reply := `{"jsonrpc":"2.0","id":"srv-2","error":{"code":-1,"message":"declined"}}`
+	if resp := post(t, ts, id, "application/json", reply); resp.StatusCode != http.StatusAccepted {
+		t.Errorf("client response: status = %d, want 202", resp.StatusCode)
+	}
+}
+
+func TestHTTP_DNSRebinding(t *testing.T) {
+	ts := newTestServer(t, HTTPOptions{AllowedOrigins: []string{"https://app.example.com"}})
+
+	tests := []struct {
+		name   string
+		host   string // Host header; empty keeps the loopback address
+		origin string
+		want   int
+	}{
+		{"no origin", "", "", http.StatusOK},
+		{"loopback origin", "", "http://localhost:3000", http.StatusOK},
+		{"allowed origin", "", "https://app.example.com", http.StatusOK},
+		{"foreign origin", "", "http://evil.example", http.StatusForbidden},
+		{"rebound host", "evil.example:8080", "http://evil.example:8080", http.StatusForbidden},
+		{"foreign host", "evil.example:8080", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/mcp", strings.NewReader(initializeBody))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json, text/event-stream")
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTP_BearerToken(t *testing.T) {
+	ts := newTestServer(t, HTTPOptions{Token: "secret"})
+
+	if resp := post(t, ts, "", "application/json", initializeBody); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("no token: status = %d, want 401", resp.StatusCode)
+	}
+	if resp := post(t, ts, "", "application/json", initializeBody, "Authorization", "Bearer wrong"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want 401", resp.StatusCode)
+	}
+	if resp := post(t, ts, "", "application/json", initializeBody, "Authorization", "Bearer secret"); resp.StatusCode != http.StatusOK {
+		t.Errorf("valid token: status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestHTTP_EventStream(t *testing.T) {
+	ts := newTestServer(t, HTTPOptions{})
+	id := initialize(t, ts)
+
+	call := `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"sync_bookmarks","arguments":{}}}`
+	resp := post(t, ts, id, "application/json, text/event-stream", call)
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The log notification precedes the response on the same stream
+	events := strings.Split(strings.TrimSpace(string(body)), "\n\n")
+	if len(events) < 2 {
+		t.Fatalf("got %d events, want notification and response:\n%s", len(events), body)
+	}
+	if !strings.Contains(events[0], `"notifications/message"`) {
+		t.Errorf("first event is not a log notification: %s", events[0])
+	}
+	if last := events[len(events)-1]; !strings.Contains(last, `"id":3`) || !strings.Contains(last, "Synced 0 bookmarks") {
+		t.Errorf("last event is not the response: %s", last)
+	}
+}
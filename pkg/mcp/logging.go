@@ -0,0 +1,143 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// logLevels lists the MCP (syslog) logging levels in increasing severity.
+var logLevels = []string{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// defaultLogLevel is the minimum level sent before a client calls
+// logging/setLevel.
+const defaultLogLevel = "info"
+
+func logLevelIndex(level string) int {
+	for i, l := range logLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// session holds the state of one connected client.
+type session struct {
+	// id is the Mcp-Session-Id of an HTTP session (empty for stdio).
+	id string
+
+	// send delivers a server-initiated message to the client.
+	send func(msg interface{}) error
+
+	mu            sync.Mutex
+	logLevel      int
+	subscriptions map[string]bool
+}
+
+func newSession(send func(msg interface{}) error) *session {
+	return &session{
+		send:          send,
+		logLevel:      logLevelIndex(defaultLogLevel),
+		subscriptions: make(map[string]bool),
+	}
+}
+
+type sessionKey struct{}
+
+func withSession(ctx context.Context, sess *session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sess)
+}
+
+func sessionFrom(ctx context.Context) *session {
+	sess, _ := ctx.Value(sessionKey{}).(*session)
+	return sess
+}
+
+type senderKey struct{}
+
+// withSender routes notifications raised while handling a request to
+// send instead of the session's default channel. The HTTP transport uses
+// this to stream notifications on the POST response.
+func withSender(ctx context.Context, send func(msg interface{}) error) context.Context {
+	return context.WithValue(ctx, senderKey{}, send)
+}
+
+// notify sends a notification to the client of the current request.
+func (s *Server) notify(ctx context.Context, method string, params interface{}) {
+	msg := &Notification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	}
+
+	if send, ok := ctx.Value(senderKey{}).(func(msg interface{}) error); ok {
+		_ = send(msg)
+		return
+	}
+	if sess := sessionFrom(ctx); sess != nil {
+		_ = sess.send(msg)
+	}
+}
+
+// log sends a notifications/message to the client of the current request,
+// if level is at or above the level the client asked for.
+func (s *Server) log(ctx context.Context, level string, data interface{}) {
+	sess := sessionFrom(ctx)
+	if sess == nil {
+		return
+	}
+
+	sess.mu.Lock()
+	minLevel := sess.logLevel
+	sess.mu.Unlock()
+	if logLevelIndex(level) < minLevel {
+		return
+	}
+
+	s.notify(ctx, "notifications/message", map[string]interface{}{
+		"level":  level,
+		"logger": "favs",
+		"data":   data,
+	})
+}
+
+func (s *Server) handleSetLevel(ctx context.Context, req *Request) *Response {
+	var params struct {
+		Level string `json:"level"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return errorResponse(req.ID, -32602, "Invalid params")
+	}
+
+	idx := logLevelIndex(params.Level)
+	if idx < 0 {
+		return errorResponse(req.ID, -32602, "Invalid log level: "+params.Level)
+	}
+
+	if sess := sessionFrom(ctx); sess != nil {
+		sess.mu.Lock()
+		sess.logLevel = idx
+		sess.mu.Unlock()
+	}
+
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result:  map[string]interface{}{},
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+	"github.com/cloudygreybeard/favs/pkg/config"
+)
+
+// testSession returns a context with a session that records the
+// notifications/message sent to it, as "level: data".
+func testSession() (context.Context, *[]string) {
+	var logged []string
+	sess := newSession(func(msg interface{}) error {
+		if n, ok := msg.(*Notification); ok && n.Method == "notifications/message" {
+			params := n.Params.(map[string]interface{})
+			logged = append(logged, fmt.Sprintf("%s: %v", params["level"], params["data"]))
+		}
+		return nil
+	})
+	return withSession(context.Background(), sess), &logged
+}
+
+func setLevel(t *testing.T, s *Server, ctx context.Context, level string) *Response {
+	t.Helper()
+	params, _ := json.Marshal(map[string]string{"level": level})
+	return s.handleRequest(ctx, &Request{JSONRPC: "2.0", ID: 1, Method: "logging/setLevel", Params: params})
+}
+
+func TestSetLevel(t *testing.T) {
+	s := NewServer(config.Default())
+	ctx, logged := testSession()
+
+	s.log(ctx, "debug", "hidden by the default level")
+	s.log(ctx, "info", "shown")
+
+	if resp := setLevel(t, s, ctx, "error"); resp.Error != nil {
+		t.Fatalf("setLevel error: %+v", resp.Error)
+	}
+	s.log(ctx, "warning", "hidden")
+	s.log(ctx, "critical", "shown")
+
+	if resp := setLevel(t, s, ctx, "loud"); resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("setLevel(loud) = %+v, want invalid params", resp)
+	}
+
+	if got := strings.Join(*logged, "|"); got != "info: shown|critical: shown" {
+		t.Errorf("logged %q", got)
+	}
+}
+
+func TestFilterWarningsLogged(t *testing.T) {
+	s := NewServer(config.Default())
+	ctx, logged := testSession()
+	collection := &bookmark.Collection{Bookmarks: []bookmark.Bookmark{
+		{Title: "Go", URL: "https://go.dev/"},
+		{Title: "Notes", URL: "file:///home/me/notes.txt"},
+		{Title: "Pixel", URL: "data:image/png;base64,AAAA"},
+	}}
+
+	s.newPipeline(ctx).Filter(collection)
+	if len(*logged) != 2 || !strings.HasPrefix((*logged)[0], "warning: ") || !strings.Contains((*logged)[0], "file://") ||
+		(*logged)[1] != "info: excluded 1 bookmarks by filter rules" {
+		t.Fatalf("logged %q, want a file: warning and the excluded count", *logged)
+	}
+
+	*logged = nil
+	setLevel(t, s, ctx, "warning")
+	s.newPipeline(ctx).Filter(collection)
+	if len(*logged) != 1 || !strings.HasPrefix((*logged)[0], "warning: ") {
+		t.Errorf("logged %q at level warning, want only the warning", *logged)
+	}
+}
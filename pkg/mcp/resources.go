@@ -0,0 +1,216 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+)
+
+// uriScheme prefixes every favs resource URI.
+const uriScheme = "favs://"
+
+// resourceTemplates are the parameterised resources advertised through
+// resources/templates/list. Every template accepts ?format=<output>,
+// naming any registered output adapter.
+var resourceTemplates = []ResourceTemplate{
+	{
+		URITemplate: "favs://{browser}/{profile}{?format}",
+		Name:        "Profile Bookmarks",
+		Description: "Bookmarks from one profile of a browser or source",
+		MimeType:    "application/json",
+	},
+	{
+		URITemplate: "favs://folder{/path*}{?format}",
+		Name:        "Folder Bookmarks",
+		Description: "Bookmarks in a folder and its subfolders; path lists the folder names from the root",
+		MimeType:    "application/json",
+	},
+	{
+		URITemplate: "favs://tag/{tag}{?format}",
+		Name:        "Tagged Bookmarks",
+		Description: "Bookmarks with the given tag",
+		MimeType:    "application/json",
+	},
+}
+
+// resourceKind identifies the subset of bookmarks a resource selects.
+type resourceKind int
+
+const (
+	resourceAll resourceKind = iota
+	resourceSource
+	resourceFolder
+	resourceTag
+)
+
+// resourceView is a parsed resource URI.
+type resourceView struct {
+	kind    resourceKind
+	source  string   // resourceSource: input adapter or source name
+	profile string   // resourceSource: optional profile
+	folder  []string // resourceFolder: folder path prefix
+	tag     string   // resourceTag
+	format  string   // output adapter name
+}
+
+// parseResourceURI parses favs://all, favs://markdown, favs://<browser>,
+// favs://<browser>/<profile>, favs://folder/<path> and favs://tag/<tag>,
+// each with an optional ?format= query.
+func parseResourceURI(uri string) (resourceView, error) {
+	rest, ok := strings.CutPrefix(uri, uriScheme)
+	if !ok {
+		return resourceView{}, fmt.Errorf("unsupported resource URI: %s", uri)
+	}
+
+	rest, rawQuery, _ := strings.Cut(rest, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return resourceView{}, fmt.Errorf("invalid query in %s: %w", uri, err)
+	}
+
+	var segments []string
+	for _, seg := range strings.Split(strings.Trim(rest, "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		unescaped, err := url.PathUnescape(seg)
+		if err != nil {
+			return resourceView{}, fmt.Errorf("invalid path in %s: %w", uri, err)
+		}
+		segments = append(segments, unescaped)
+	}
+	if len(segments) == 0 {
+		return resourceView{}, fmt.Errorf("unsupported resource URI: %s", uri)
+	}
+
+	// Keep the cases in step with config.ReservedSourceNames
+	view := resourceView{format: "json"}
+	switch head, tail := segments[0], segments[1:]; head {
+	case "all":
+		view.kind = resourceAll
+	case "markdown":
+		view.kind = resourceAll
+		view.format = "markdown"
+	case "folder":
+		if len(tail) == 0 {
+			return resourceView{}, fmt.Errorf("missing folder path in %s", uri)
+		}
+		view.kind = resourceFolder
+		view.folder = tail
+	case "tag":
+		if len(tail) == 0 {
+			return resourceView{}, fmt.Errorf("missing tag in %s", uri)
+		}
+		view.kind = resourceTag
+		view.tag = strings.Join(tail, "/")
+	default:
+		view.kind = resourceSource
+		view.source = head
+		view.profile = strings.Join(tail, "/")
+	}
+
+	if format := query.Get("format"); format != "" {
+		view.format = format
+	}
+	return view, nil
+}
+
+// match reports whether a bookmark belongs to the view.
+func (v resourceView) match(b bookmark.Bookmark) bool {
+	switch v.kind {
+	case resourceSource:
+		return b.Source == v.source && (v.profile == "" || b.Profile == v.profile ||
+			(b.ProfileName != "" && strings.EqualFold(b.ProfileName, v.profile)))
+	case resourceFolder:
+		if len(b.FolderPath) < len(v.folder) {
+			return false
+		}
+		for i, name := range v.folder {
+			if b.FolderPath[i] != name {
+				return false
+			}
+		}
+		return true
+	case resourceTag:
+		for _, t := range b.Tags {
+			if strings.EqualFold(t, v.tag) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// apply returns the subset of a collection selected by the view.
+// Source counts are recomputed for the subset; sources that contribute
+// no bookmarks are dropped. The result never shares its bookmark slice
+// with c, since renderers may sort it in place.
+func (v resourceView) apply(c *bookmark.Collection) *bookmark.Collection {
+	if v.kind == resourceAll {
+		return &bookmark.Collection{
+			Bookmarks: append([]bookmark.Bookmark(nil), c.Bookmarks...),
+			Sources:   c.Sources,
+		}
+	}
+
+	result := bookmark.NewCollection()
+	counts := make(map[string]int)
+	firsts := make(map[string]bookmark.Bookmark)
+	for _, b := range c.Bookmarks {
+		if v.match(b) {
+			result.Bookmarks = append(result.Bookmarks, b)
+			if counts[b.Source] == 0 {
+				firsts[b.Source] = b
+			}
+			counts[b.Source]++
+		}
+	}
+
+	for _, s := range c.Sources {
+		if counts[s.ID()] == 0 {
+			continue
+		}
+		if v.kind == resourceSource && v.profile != "" {
+			// The profile may have been named rather than given by ID
+			first := firsts[s.ID()]
+			s.Profile, s.ProfileName = first.Profile, first.ProfileName
+		}
+		s.Count = counts[s.ID()]
+		result.Sources = append(result.Sources, s)
+	}
+	return result
+}
+
+// mimeTypes maps output adapter names to resource MIME types.
+var mimeTypes = map[string]string{
+	"json":     "application/json",
+	"markdown": "text/markdown",
+	"yaml":     "application/yaml",
+	"opml":     "text/x-opml",
+	"html":     "text/html",
+}
+
+func mimeTypeFor(format string) string {
+	if mt, ok := mimeTypes[format]; ok {
+		return mt
+	}
+	return "text/plain"
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"testing"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+)
+
+func TestParseResourceURI(t *testing.T) {
+	collection := &bookmark.Collection{
+		Bookmarks: []bookmark.Bookmark{
+			{Title: "Go", URL: "https://go.dev", FolderPath: []string{"Bookmarks Bar", "Dev", "Go"}, Source: "chrome", Profile: "Default", Tags: []string{"go"}},
+			{Title: "Work", URL: "https://work.example.com", FolderPath: []string{"Bookmarks Bar", "Work"}, Source: "chrome", Profile: "Profile 1"},
+			{Title: "Docs", URL: "https://docs.example.com", FolderPath: []string{"toolbar", "Dev"}, Source: "firefox", Profile: "abc.default", Tags: []string{"docs", "go"}},
+			{Title: "Pipelines", URL: "https://ci.example.com", FolderPath: []string{"Bookmarks Bar", "CI/CD", "Build & Deploy"}, Source: "chrome", Profile: "Default"},
+		},
+		Sources: []bookmark.SourceInfo{
+			{Name: "chrome", Profile: "Default", Count: 3},
+			{Name: "firefox", Profile: "abc.default", Count: 1},
+		},
+	}
+
+	tests := []struct {
+		uri     string
+		format  string
+		want    []string
+		wantErr bool
+	}{
+		{uri: "favs://all", format: "json", want: []string{"Go", "Work", "Docs", "Pipelines"}},
+		{uri: "favs://markdown", format: "markdown", want: []string{"Go", "Work", "Docs", "Pipelines"}},
+		{uri: "favs://chrome", format: "json", want: []string{"Go", "Work", "Pipelines"}},
+		{uri: "favs://chrome/Profile%201?format=yaml", format: "yaml", want: []string{"Work"}},
+		{uri: "favs://folder/Bookmarks%20Bar/Dev", format: "json", want: []string{"Go"}},
+		{uri: "favs://folder/Bookmarks Bar", format: "json", want: []string{"Go", "Work", "Pipelines"}},
+		{uri: "favs://folder/Bookmarks%20Bar/CI%2FCD/Build%20%26%20Deploy", format: "json", want: []string{"Pipelines"}},
+		{uri: "favs://folder/Bookmarks%20Bar/CI", format: "json", want: nil},
+		{uri: "favs://tag/go?format=markdown", format: "markdown", want: []string{"Go", "Docs"}},
+		{uri: "favs://folder", wantErr: true},
+		{uri: "favs://", wantErr: true},
+		{uri: "https://example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			view, err := parseResourceURI(tt.uri)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", view)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if view.format != tt.format {
+				t.Errorf("format = %q, want %q", view.format, tt.format)
+			}
+
+			subset := view.apply(collection)
+			var got []string
+			for _, b := range subset.Bookmarks {
+				got = append(got, b.Title)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
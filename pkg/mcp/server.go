@@ -21,25 +21,50 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/cloudygreybeard/favs/pkg/adapter"
 	"github.com/cloudygreybeard/favs/pkg/bookmark"
 	"github.com/cloudygreybeard/favs/pkg/config"
+	"github.com/cloudygreybeard/favs/pkg/diag"
+	"github.com/cloudygreybeard/favs/pkg/index"
 	"github.com/cloudygreybeard/favs/pkg/input"
-	"github.com/cloudygreybeard/favs/pkg/output"
+	"github.com/cloudygreybeard/favs/pkg/pipeline"
 )
 
+// defaultSearchLimit caps search_bookmarks results when no limit is given.
+const defaultSearchLimit = 20
+
 // Server implements an MCP server for bookmark resources.
 type Server struct {
-	config  config.Config
-	cache   *bookmark.Collection
-	cacheMu sync.RWMutex
+	config   config.Config
+	pipeline *pipeline.Pipeline
+	cache    *bookmark.Collection
+	cacheMu  sync.RWMutex
+
+	// diagnostics are those of the pipeline run that filled the cache.
+	diagnostics *diag.Diagnostics
+
+	// readMu serialises pipeline runs, which configure shared adapters.
+	readMu sync.Mutex
+
+	sessionsMu sync.Mutex
+	sessions   map[*session]struct{}
+
+	indexOnce sync.Once
+	index     *index.Index
 }
 
 // NewServer creates a new MCP server.
 func NewServer(cfg config.Config) *Server {
-	return &Server{config: cfg}
+	return &Server{
+		config:   cfg,
+		pipeline: pipeline.New(cfg),
+		sessions: make(map[*session]struct{}),
+	}
 }
 
 // Run starts the MCP server, reading JSON-RPC from stdin and writing to stdout.
@@ -47,6 +72,16 @@ func (s *Server) Run(ctx context.Context) error {
 	decoder := json.NewDecoder(os.Stdin)
 	encoder := json.NewEncoder(os.Stdout)
 
+	var encMu sync.Mutex
+	sess := newSession(func(msg interface{}) error {
+		encMu.Lock()
+		defer encMu.Unlock()
+		return encoder.Encode(msg)
+	})
+	ctx = withSession(ctx, sess)
+	s.addSession(sess)
+	defer s.removeSession(sess)
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -63,20 +98,42 @@ func (s *Server) Run(ctx context.Context) error {
 		}
 
 		resp := s.handleRequest(ctx, &req)
-		if err := encoder.Encode(resp); err != nil {
+		if resp == nil {
+			continue
+		}
+		if err := sess.send(resp); err != nil {
 			fmt.Fprintf(os.Stderr, "Error encoding response: %v\n", err)
 		}
 	}
 }
 
+// handleRequest dispatches a JSON-RPC message. Notifications (messages
+// without an ID) never get a response, so nil is returned for them.
 func (s *Server) handleRequest(ctx context.Context, req *Request) *Response {
+	if req.ID == nil || req.Method == "" {
+		// Notifications such as notifications/initialized and
+		// notifications/cancelled need no handling, and neither do
+		// responses from the client, which have no method.
+		return nil
+	}
+
 	switch req.Method {
 	case "initialize":
 		return s.handleInitialize(req)
+	case "ping":
+		return &Response{JSONRPC: "2.0", ID: req.ID, Result: map[string]interface{}{}}
+	case "logging/setLevel":
+		return s.handleSetLevel(ctx, req)
 	case "resources/list":
 		return s.handleResourcesList(req)
+	case "resources/templates/list":
+		return s.handleResourceTemplatesList(req)
 	case "resources/read":
 		return s.handleResourcesRead(ctx, req)
+	case "resources/subscribe":
+		return s.handleSubscribe(ctx, req, true)
+	case "resources/unsubscribe":
+		return s.handleSubscribe(ctx, req, false)
 	case "tools/list":
 		return s.handleToolsList(req)
 	case "tools/call":
@@ -86,22 +143,40 @@ func (s *Server) handleRequest(ctx context.Context, req *Request) *Response {
 	}
 }
 
+// protocolVersions lists the supported MCP protocol versions, newest first.
+var protocolVersions = []string{"2025-03-26", "2024-11-05"}
+
 func (s *Server) handleInitialize(req *Request) *Response {
+	var params struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	_ = json.Unmarshal(req.Params, &params)
+
+	// Echo the client's version if supported, otherwise offer the latest
+	version := protocolVersions[0]
+	for _, v := range protocolVersions {
+		if v == params.ProtocolVersion {
+			version = v
+			break
+		}
+	}
+
 	return &Response{
 		JSONRPC: "2.0",
 		ID:      req.ID,
 		Result: map[string]interface{}{
-			"protocolVersion": "2024-11-05",
+			"protocolVersion": version,
 			"serverInfo": map[string]string{
 				"name":    "favs",
 				"version": "1.0.0",
 			},
 			"capabilities": map[string]interface{}{
 				"resources": map[string]bool{
-					"subscribe":   false,
-					"listChanged": false,
+					"subscribe":   true,
+					"listChanged": true,
 				},
-				"tools": map[string]interface{}{},
+				"tools":   map[string]interface{}{},
+				"logging": map[string]interface{}{},
 			},
 		},
 	}
@@ -136,6 +211,14 @@ func (s *Server) handleResourcesList(req *Request) *Response {
 			MimeType:    "application/json",
 		})
 	}
+	for _, src := range s.config.Sources {
+		resources = append(resources, Resource{
+			URI:         fmt.Sprintf("favs://%s", src.Name),
+			Name:        fmt.Sprintf("%s Bookmarks", src.Name),
+			Description: fmt.Sprintf("Bookmarks from the %s source (%s)", src.Name, src.Adapter),
+			MimeType:    "application/json",
+		})
+	}
 
 	return &Response{
 		JSONRPC: "2.0",
@@ -146,6 +229,16 @@ func (s *Server) handleResourcesList(req *Request) *Response {
 	}
 }
 
+func (s *Server) handleResourceTemplatesList(req *Request) *Response {
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"resourceTemplates": resourceTemplates,
+		},
+	}
+}
+
 func (s *Server) handleResourcesRead(ctx context.Context, req *Request) *Response {
 	var params struct {
 		URI string `json:"uri"`
@@ -154,32 +247,32 @@ func (s *Server) handleResourcesRead(ctx context.Context, req *Request) *Respons
 		return errorResponse(req.ID, -32602, "Invalid params")
 	}
 
-	collection, err := s.getBookmarks(ctx, params.URI)
+	view, err := parseResourceURI(params.URI)
 	if err != nil {
-		return errorResponse(req.ID, -32000, err.Error())
+		return errorResponse(req.ID, -32002, err.Error())
 	}
-
-	// Determine format from URI
-	format := "json"
-	if params.URI == "favs://markdown" {
-		format = "markdown"
+	if view.kind == resourceSource {
+		_, isSource := s.config.GetSource(view.source)
+		if _, ok := adapter.GetInput(view.source); !ok && !isSource {
+			return errorResponse(req.ID, -32002, "Resource not found: "+params.URI)
+		}
 	}
-
-	outAdapter, ok := adapter.GetOutput(format)
-	if !ok {
-		return errorResponse(req.ID, -32000, "Output adapter not found")
+	if _, ok := adapter.GetOutput(view.format); !ok {
+		return errorResponse(req.ID, -32602, fmt.Sprintf("unknown format: %s (available: %v)", view.format, adapter.ListOutputs()))
 	}
 
-	data, err := outAdapter.Render(collection, output.DefaultRenderOptions())
+	collection, err := s.getBookmarks(ctx, params.URI)
 	if err != nil {
 		return errorResponse(req.ID, -32000, err.Error())
 	}
 
-	mimeType := "application/json"
-	if format == "markdown" {
-		mimeType = "text/markdown"
+	data, err := s.pipeline.Render(view.apply(collection), view.format, s.pipeline.RenderOptions())
+	if err != nil {
+		return errorResponse(req.ID, -32000, err.Error())
 	}
 
+	mimeType := mimeTypeFor(view.format)
+
 	return &Response{
 		JSONRPC: "2.0",
 		ID:      req.ID,
@@ -207,18 +300,42 @@ func (s *Server) handleToolsList(req *Request) *Response {
 		},
 		{
 			Name:        "search_bookmarks",
-			Description: "Search bookmarks by title or URL",
+			Description: "Search bookmarks with a fielded query, most relevant first",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
 					"query": map[string]interface{}{
-						"type":        "string",
-						"description": "Search query",
+						"type": "string",
+						"description": "Search query. Free text matches title, URL, folders, tags and description. " +
+							"Fields: tag:go folder:Dev/Tools site:github.com title: url: source: profile: " +
+							"added:>2025-01-01. Use \"quotes\" for phrases and -term to exclude.",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Maximum results to return (default %d, 0 for all)", defaultSearchLimit),
+					},
+					"offset": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of results to skip",
 					},
 				},
 				"required": []string{"query"},
 			},
 		},
+		{
+			Name:        "get_diagnostics",
+			Description: "List problems found while loading bookmarks: unreadable browsers or profiles, invalid filter rules, suspicious and excluded bookmarks",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"severity": map[string]interface{}{
+						"type":        "string",
+						"enum":        diag.Severities,
+						"description": "Minimum severity to return (default info, i.e. everything)",
+					},
+				},
+			},
+		},
 	}
 
 	return &Response{
@@ -244,6 +361,8 @@ func (s *Server) handleToolsCall(ctx context.Context, req *Request) *Response {
 		return s.toolSyncBookmarks(ctx, req)
 	case "search_bookmarks":
 		return s.toolSearchBookmarks(ctx, req, params.Arguments)
+	case "get_diagnostics":
+		return s.toolGetDiagnostics(ctx, req, params.Arguments)
 	default:
 		return errorResponse(req.ID, -32602, "Unknown tool")
 	}
@@ -275,38 +394,109 @@ func (s *Server) toolSyncBookmarks(ctx context.Context, req *Request) *Response
 }
 
 func (s *Server) toolSearchBookmarks(ctx context.Context, req *Request, args json.RawMessage) *Response {
-	var searchArgs struct {
-		Query string `json:"query"`
-	}
+	searchArgs := struct {
+		Query  string `json:"query"`
+		Limit  int    `json:"limit"`
+		Offset int    `json:"offset"`
+	}{Limit: defaultSearchLimit}
 	if err := json.Unmarshal(args, &searchArgs); err != nil {
 		return errorResponse(req.ID, -32602, "Invalid search arguments")
 	}
 
+	query, err := bookmark.ParseQuery(searchArgs.Query)
+	if err != nil {
+		return errorResponse(req.ID, -32602, err.Error())
+	}
+
 	collection, err := s.getBookmarks(ctx, "favs://all")
 	if err != nil {
 		return errorResponse(req.ID, -32000, err.Error())
 	}
 
-	// Simple search
-	var matches []bookmark.Bookmark
-	query := searchArgs.Query
-	for _, b := range collection.Bookmarks {
-		if containsIgnoreCase(b.Title, query) || containsIgnoreCase(b.URL, query) {
-			matches = append(matches, b)
-		}
+	opts := bookmark.SearchOptions{
+		Limit:  searchArgs.Limit,
+		Offset: searchArgs.Offset,
+	}
+	var matches []bookmark.SearchResult
+	var total int
+	if ix := s.searchIndex(ctx); ix != nil {
+		matches, total = ix.Search(query, opts)
+	} else {
+		matches, total = bookmark.Search(collection.Bookmarks, query, opts)
 	}
 
 	// Format results
-	var results []map[string]string
-	for _, b := range matches {
-		results = append(results, map[string]string{
-			"title": b.Title,
-			"url":   b.URL,
+	type searchResult struct {
+		Title  string   `json:"title"`
+		URL    string   `json:"url"`
+		Folder string   `json:"folder,omitempty"`
+		Source string   `json:"source,omitempty"`
+		Tags   []string `json:"tags,omitempty"`
+	}
+	results := []searchResult{}
+	for _, m := range matches {
+		b := m.Bookmark
+		results = append(results, searchResult{
+			Title:  b.Title,
+			URL:    b.URL,
+			Folder: strings.Join(b.FolderPath, "/"),
+			Source: b.Source,
+			Tags:   b.Tags,
 		})
 	}
 
 	resultJSON, _ := json.MarshalIndent(results, "", "  ")
 
+	summary := fmt.Sprintf("Found %d matches", total)
+	if len(results) < total {
+		summary += fmt.Sprintf(" (showing %d-%d)", searchArgs.Offset+1, searchArgs.Offset+len(results))
+	}
+
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": fmt.Sprintf("%s:\n%s", summary, string(resultJSON)),
+				},
+			},
+		},
+	}
+}
+
+func (s *Server) toolGetDiagnostics(ctx context.Context, req *Request, args json.RawMessage) *Response {
+	diagArgs := struct {
+		Severity diag.Severity `json:"severity"`
+	}{Severity: diag.Info}
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &diagArgs); err != nil {
+			return errorResponse(req.ID, -32602, "Invalid diagnostics arguments")
+		}
+	}
+	if !slices.Contains(diag.Severities, diagArgs.Severity) {
+		return errorResponse(req.ID, -32602, fmt.Sprintf("unknown severity: %s (available: %v)", diagArgs.Severity, diag.Severities))
+	}
+
+	if _, err := s.getBookmarks(ctx, "favs://all"); err != nil {
+		return errorResponse(req.ID, -32000, err.Error())
+	}
+	s.cacheMu.RLock()
+	diags := s.diagnostics
+	s.cacheMu.RUnlock()
+
+	items := diags.AtLeast(diagArgs.Severity)
+	if items == nil {
+		items = []diag.Diagnostic{}
+	}
+	itemsJSON, _ := json.MarshalIndent(items, "", "  ")
+
+	summary := diags.Summary()
+	if summary == "" {
+		summary = "no diagnostics"
+	}
+
 	return &Response{
 		JSONRPC: "2.0",
 		ID:      req.ID,
@@ -314,7 +504,7 @@ func (s *Server) toolSearchBookmarks(ctx context.Context, req *Request, args jso
 			"content": []map[string]interface{}{
 				{
 					"type": "text",
-					"text": fmt.Sprintf("Found %d matches:\n%s", len(matches), string(resultJSON)),
+					"text": fmt.Sprintf("Last load: %s\n%s", summary, string(itemsJSON)),
 				},
 			},
 		},
@@ -331,89 +521,85 @@ func (s *Server) getBookmarks(ctx context.Context, uri string) (*bookmark.Collec
 	}
 	s.cacheMu.RUnlock()
 
-	// Read from all available inputs
-	collection := bookmark.NewCollection()
-
-	for _, name := range adapter.ListInputs() {
-		inp, ok := adapter.GetInput(name)
-		if !ok {
-			continue
-		}
-
-		inputCfg := s.config.GetInputConfig(name)
-		if !inputCfg.Enabled {
-			continue
-		}
+	s.readMu.Lock()
+	defer s.readMu.Unlock()
 
-		if !inp.Available() {
-			continue
-		}
+	// Another request may have filled the cache while we waited
+	s.cacheMu.RLock()
+	cached := s.cache
+	s.cacheMu.RUnlock()
+	if cached != nil {
+		return cached, nil
+	}
 
-		if err := inp.Configure(input.Config{
-			Enabled:    true,
-			Profile:    "",
-			CustomPath: inputCfg.CustomPath,
-		}); err != nil {
-			continue
+	// Run the same pipeline as the CLI's --all mode, through the search
+	// index when enabled so unchanged inputs are not re-read
+	p := s.newPipeline(ctx)
+	var collection *bookmark.Collection
+	if ix := s.searchIndex(ctx); ix != nil {
+		if _, err := index.Sync(ctx, ix, p); err != nil {
+			s.log(ctx, "warning", fmt.Sprintf("updating search index: %v", err))
 		}
-
-		bookmarks, err := inp.Read(ctx)
+		// Indexed bookmarks are already transformed; only merge them
+		collection = p.Merge(ix.Collection())
+	} else {
+		var err error
+		collection, err = p.Run(ctx, pipeline.ReadOptions{All: true})
 		if err != nil {
-			continue
-		}
-
-		if len(bookmarks) > 0 {
-			profile := ""
-			if len(bookmarks) > 0 {
-				profile = bookmarks[0].Profile
-			}
-			collection.Add(bookmarks, bookmark.SourceInfo{
-				Name:    name,
-				Profile: profile,
-				Path:    inp.Path(),
-			})
+			return nil, err
 		}
 	}
 
+	s.log(ctx, "info", fmt.Sprintf("loaded %d bookmarks from %d sources", collection.Count(), len(collection.Sources)))
+
 	// Update cache
 	s.cacheMu.Lock()
 	s.cache = collection
+	s.diagnostics = p.Diagnostics()
 	s.cacheMu.Unlock()
 
 	return collection, nil
 }
 
-func containsIgnoreCase(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
-		(len(s) > 0 && containsIgnoreCaseImpl(s, substr)))
-}
-
-func containsIgnoreCaseImpl(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if equalIgnoreCase(s[i:i+len(substr)], substr) {
-			return true
+// searchIndex opens the on-disk search index on first use. It returns
+// nil if the index is disabled or cannot be opened.
+func (s *Server) searchIndex(ctx context.Context) *index.Index {
+	s.indexOnce.Do(func() {
+		if !s.config.Index.Enabled {
+			return
 		}
-	}
-	return false
+		ix, err := index.Open(s.config.IndexPath())
+		if err != nil {
+			s.log(ctx, "warning", fmt.Sprintf("search index disabled: %v", err))
+			return
+		}
+		s.index = ix
+	})
+	return s.index
 }
 
-func equalIgnoreCase(a, b string) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for i := 0; i < len(a); i++ {
-		ca, cb := a[i], b[i]
-		if ca >= 'A' && ca <= 'Z' {
-			ca += 32
-		}
-		if cb >= 'A' && cb <= 'Z' {
-			cb += 32
-		}
-		if ca != cb {
-			return false
-		}
+// newPipeline creates a pipeline that reports read errors and filter
+// warnings to the client through logging notifications.
+func (s *Server) newPipeline(ctx context.Context) *pipeline.Pipeline {
+	p := pipeline.New(s.config)
+	p.Hooks = pipeline.Hooks{
+		AfterReadDone: func(inp input.Adapter, bookmarks []bookmark.Bookmark, elapsed time.Duration, err error) {
+			if err != nil {
+				s.log(ctx, "error", fmt.Sprintf("reading %s: %v", inp.Name(), err))
+				return
+			}
+			s.log(ctx, "debug", fmt.Sprintf("read %d bookmarks from %s in %s", len(bookmarks), inp.Name(), elapsed.Round(time.Microsecond)))
+		},
+		AfterFilter: func(result bookmark.FilterResult) {
+			for _, w := range result.Warnings {
+				s.log(ctx, "warning", w)
+			}
+			if result.Excluded > 0 {
+				s.log(ctx, "info", fmt.Sprintf("excluded %d bookmarks by filter rules", result.Excluded))
+			}
+		},
 	}
-	return true
+	return p
 }
 
 func errorResponse(id interface{}, code int, message string) *Response {
@@ -445,6 +631,13 @@ type Response struct {
 	Error   *Error      `json:"error,omitempty"`
 }
 
+// Notification represents a JSON-RPC notification sent by the server.
+type Notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
 // Error represents a JSON-RPC error.
 type Error struct {
 	Code    int    `json:"code"`
@@ -459,6 +652,14 @@ type Resource struct {
 	MimeType    string `json:"mimeType,omitempty"`
 }
 
+// ResourceTemplate represents a parameterised MCP resource (RFC 6570 URI template).
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
 // Tool represents an MCP tool.
 type Tool struct {
 	Name        string                 `json:"name"`
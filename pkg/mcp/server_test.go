@@ -0,0 +1,60 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudygreybeard/favs/pkg/config"
+	_ "github.com/cloudygreybeard/favs/pkg/input/opml"
+)
+
+func TestGetBookmarks_Index(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "work.opml")
+	opml := `<opml version="2.0"><body><outline text="Work">` +
+		`<outline text="Wiki" htmlUrl="https://wiki.example.com/"/>` +
+		`<outline text="Wiki again" htmlUrl="https://wiki.example.com/"/>` +
+		`</outline></body></opml>`
+	if err := os.WriteFile(path, []byte(opml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{
+		Sources: []config.SourceConfig{{Name: "work", Adapter: "opml", Path: path}},
+		Index:   config.IndexConfig{Enabled: true, Path: filepath.Join(dir, "index")},
+	}
+	cfg.Pipeline.Transform.Deduplicate = true
+	cfg.Pipeline.Transform.Folders.Rename = []config.FolderRename{{From: "**/Work", To: "Work/Current"}}
+
+	// The second server reads the index the first one saved
+	for i := 0; i < 2; i++ {
+		s := NewServer(cfg)
+		c, err := s.getBookmarks(context.Background(), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Count() != 1 {
+			t.Fatalf("got %d bookmarks, want the duplicates merged", c.Count())
+		}
+		if got := strings.Join(c.Bookmarks[0].FolderPath, "/"); got != "Work/Current" {
+			t.Errorf("run %d: FolderPath = %s, want Work/Current", i, got)
+		}
+	}
+}
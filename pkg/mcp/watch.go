@@ -0,0 +1,122 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudygreybeard/favs/pkg/watch"
+)
+
+// Watch polls the files behind every configured input and, when one
+// changes, invalidates the bookmark cache and notifies clients: every
+// session gets notifications/resources/list_changed, and sessions that
+// subscribed to a resource get notifications/resources/updated for it.
+// Watch blocks until ctx is cancelled.
+func (s *Server) Watch(ctx context.Context, interval time.Duration) {
+	w := watch.New(interval)
+	w.Set(s.watchPaths())
+
+	w.Run(ctx, func(changed []string) {
+		s.sourcesChanged(changed)
+		// Profiles may have been added or removed
+		w.Set(s.watchPaths())
+	})
+}
+
+func (s *Server) watchPaths() []string {
+	s.readMu.Lock()
+	defer s.readMu.Unlock()
+	return s.pipeline.WatchPaths()
+}
+
+// sourcesChanged invalidates the cache and notifies every session.
+func (s *Server) sourcesChanged(changed []string) {
+	s.cacheMu.Lock()
+	s.cache = nil
+	s.cacheMu.Unlock()
+
+	msg := fmt.Sprintf("bookmark sources changed: %s", strings.Join(changed, ", "))
+	for _, sess := range s.allSessions() {
+		ctx := withSession(context.Background(), sess)
+		s.log(ctx, "info", msg)
+		s.notify(ctx, "notifications/resources/list_changed", nil)
+
+		sess.mu.Lock()
+		var uris []string
+		for uri := range sess.subscriptions {
+			uris = append(uris, uri)
+		}
+		sess.mu.Unlock()
+
+		for _, uri := range uris {
+			s.notify(ctx, "notifications/resources/updated", map[string]string{"uri": uri})
+		}
+	}
+}
+
+func (s *Server) handleSubscribe(ctx context.Context, req *Request, subscribe bool) *Response {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+		return errorResponse(req.ID, -32602, "Invalid params")
+	}
+	if _, err := parseResourceURI(params.URI); err != nil {
+		return errorResponse(req.ID, -32002, err.Error())
+	}
+
+	if sess := sessionFrom(ctx); sess != nil {
+		sess.mu.Lock()
+		if subscribe {
+			sess.subscriptions[params.URI] = true
+		} else {
+			delete(sess.subscriptions, params.URI)
+		}
+		sess.mu.Unlock()
+	}
+
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result:  map[string]interface{}{},
+	}
+}
+
+func (s *Server) addSession(sess *session) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	s.sessions[sess] = struct{}{}
+}
+
+func (s *Server) removeSession(sess *session) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	delete(s.sessions, sess)
+}
+
+func (s *Server) allSessions() []*session {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	sessions := make([]*session, 0, len(s.sessions))
+	for sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	return sessions
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+	"github.com/cloudygreybeard/favs/pkg/config"
+)
+
+func TestServer_SourcesChanged(t *testing.T) {
+	s := NewServer(config.Config{})
+	s.cache = bookmark.NewCollection()
+
+	var methods []string
+	var updated []string
+	sess := newSession(func(msg interface{}) error {
+		n := msg.(*Notification)
+		methods = append(methods, n.Method)
+		if n.Method == "notifications/resources/updated" {
+			updated = append(updated, n.Params.(map[string]string)["uri"])
+		}
+		return nil
+	})
+	s.addSession(sess)
+	ctx := withSession(context.Background(), sess)
+
+	subscribe := func(method, uri string) *Response {
+		params, _ := json.Marshal(map[string]string{"uri": uri})
+		return s.handleRequest(ctx, &Request{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	}
+	if resp := subscribe("resources/subscribe", "favs://tag/go"); resp.Error != nil {
+		t.Fatalf("subscribe: %v", resp.Error.Message)
+	}
+	if resp := subscribe("resources/subscribe", "http://example.com"); resp.Error == nil {
+		t.Error("subscribe to foreign URI succeeded")
+	}
+
+	s.sourcesChanged([]string{"/tmp/Bookmarks"})
+
+	if s.cache != nil {
+		t.Error("cache was not invalidated")
+	}
+	want := []string{"notifications/message", "notifications/resources/list_changed", "notifications/resources/updated"}
+	if len(methods) != len(want) {
+		t.Fatalf("notifications = %v, want %v", methods, want)
+	}
+	for i := range want {
+		if methods[i] != want[i] {
+			t.Errorf("notification %d = %s, want %s", i, methods[i], want[i])
+		}
+	}
+	if len(updated) != 1 || updated[0] != "favs://tag/go" {
+		t.Errorf("updated = %v", updated)
+	}
+
+	methods = nil
+	subscribe("resources/unsubscribe", "favs://tag/go")
+	s.sourcesChanged([]string{"/tmp/Bookmarks"})
+	for _, m := range methods {
+		if m == "notifications/resources/updated" {
+			t.Error("updated sent after unsubscribe")
+		}
+	}
+}
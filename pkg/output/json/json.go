@@ -75,19 +75,24 @@ func (a *Adapter) Render(collection *bookmark.Collection, opts output.RenderOpti
 		}
 		for _, s := range collection.Sources {
 			doc.Metadata.Sources = append(doc.Metadata.Sources, SourceEntry{
-				Name:    s.Name,
-				Profile: s.Profile,
-				Path:    s.Path,
-				Count:   s.Count,
+				Name:        s.Name,
+				Instance:    s.Instance,
+				Profile:     s.Profile,
+				ProfileName: s.ProfileName,
+				Path:        s.Path,
+				Count:       s.Count,
 			})
 		}
 	}
 
 	for _, b := range collection.Bookmarks {
 		entry := BookmarkEntry{
-			Title:  b.Title,
-			URL:    b.URL,
-			Folder: b.FolderPath,
+			Title:       b.Title,
+			URL:         b.URL,
+			Folder:      b.FolderPath,
+			Keyword:     b.Keyword,
+			Description: b.Description,
+			GUID:        b.GUID,
 		}
 
 		if opts.IncludeDates && !b.DateAdded.IsZero() {
@@ -102,6 +107,14 @@ func (a *Adapter) Render(collection *bookmark.Collection, opts output.RenderOpti
 		if opts.IncludeProfile {
 			entry.Source = b.Source
 			entry.Profile = b.Profile
+			entry.ProfileName = b.ProfileName
+			for _, o := range b.AlsoIn() {
+				entry.AlsoIn = append(entry.AlsoIn, OccurrenceEntry{
+					Source:  o.Source,
+					Profile: o.Profile,
+					Folder:  o.FolderPath,
+				})
+			}
 		}
 
 		doc.Bookmarks = append(doc.Bookmarks, entry)
@@ -126,19 +139,33 @@ type Metadata struct {
 
 // SourceEntry describes a bookmark source.
 type SourceEntry struct {
-	Name    string `json:"name"`
-	Profile string `json:"profile,omitempty"`
-	Path    string `json:"path,omitempty"`
-	Count   int    `json:"count"`
+	Name        string `json:"name"`
+	Instance    string `json:"instance,omitempty"`
+	Profile     string `json:"profile,omitempty"`
+	ProfileName string `json:"profile_name,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Count       int    `json:"count"`
 }
 
 // BookmarkEntry is a single bookmark in the JSON output.
 type BookmarkEntry struct {
-	Title     string   `json:"title"`
-	URL       string   `json:"url"`
-	Folder    []string `json:"folder,omitempty"`
-	DateAdded *string  `json:"date_added,omitempty"`
-	Tags      []string `json:"tags,omitempty"`
-	Source    string   `json:"source,omitempty"`
-	Profile   string   `json:"profile,omitempty"`
+	Title       string            `json:"title"`
+	URL         string            `json:"url"`
+	Folder      []string          `json:"folder,omitempty"`
+	DateAdded   *string           `json:"date_added,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Keyword     string            `json:"keyword,omitempty"`
+	Description string            `json:"description,omitempty"`
+	GUID        string            `json:"guid,omitempty"`
+	Source      string            `json:"source,omitempty"`
+	Profile     string            `json:"profile,omitempty"`
+	ProfileName string            `json:"profile_name,omitempty"`
+	AlsoIn      []OccurrenceEntry `json:"also_in,omitempty"`
+}
+
+// OccurrenceEntry is another place a merged bookmark was found.
+type OccurrenceEntry struct {
+	Source  string   `json:"source"`
+	Profile string   `json:"profile,omitempty"`
+	Folder  []string `json:"folder,omitempty"`
 }
@@ -136,7 +136,7 @@ func (a *Adapter) renderTextual(collection *bookmark.Collection, opts output.Ren
 
 			header := cases.Title(language.English).String(bm[0].Source)
 			if opts.IncludeProfile && bm[0].Profile != "" {
-				header += " / " + bm[0].Profile
+				header += " / " + bookmark.ProfileLabel(bm[0].Profile, bm[0].ProfileName)
 			}
 			sb.WriteString(fmt.Sprintf("## %s\n\n", header))
 
@@ -199,6 +199,9 @@ func (a *Adapter) renderBookmark(b bookmark.Bookmark, sb *strings.Builder, inden
 			meta = append(meta, "#"+tag)
 		}
 	}
+	if opts.IncludeProfile && len(b.AlsoIn()) > 0 {
+		meta = append(meta, "also in: "+alsoIn(b))
+	}
 
 	if len(meta) > 0 {
 		line += " *(" + strings.Join(meta, ", ") + ")*"
@@ -238,7 +241,7 @@ func (a *Adapter) renderTable(collection *bookmark.Collection, opts output.Rende
 
 			header := cases.Title(language.English).String(bm[0].Source)
 			if opts.IncludeProfile && bm[0].Profile != "" {
-				header += " / " + bm[0].Profile
+				header += " / " + bookmark.ProfileLabel(bm[0].Profile, bm[0].ProfileName)
 			}
 			sb.WriteString(fmt.Sprintf("## %s\n\n", header))
 
@@ -338,6 +341,15 @@ func (a *Adapter) renderYAML(collection *bookmark.Collection, opts output.Render
 			if b.Profile != "" {
 				sb.WriteString(fmt.Sprintf("    profile: %s\n", b.Profile))
 			}
+			if b.ProfileName != "" {
+				sb.WriteString(fmt.Sprintf("    profile_name: %s\n", yamlEscape(b.ProfileName)))
+			}
+			for i, o := range b.AlsoIn() {
+				if i == 0 {
+					sb.WriteString("    also_in:\n")
+				}
+				sb.WriteString(fmt.Sprintf("      - %s\n", yamlEscape(o.String())))
+			}
 		}
 
 		sb.WriteString("\n")
@@ -350,12 +362,21 @@ func (a *Adapter) renderYAML(collection *bookmark.Collection, opts output.Render
 
 // Helper functions
 
+// alsoIn lists the other places a merged bookmark was found.
+func alsoIn(b bookmark.Bookmark) string {
+	var places []string
+	for _, o := range b.AlsoIn() {
+		places = append(places, o.String())
+	}
+	return strings.Join(places, "; ")
+}
+
 func formatSources(sources []bookmark.SourceInfo) string {
 	var parts []string
 	for _, s := range sources {
-		part := s.Name
+		part := s.ID()
 		if s.Profile != "" {
-			part += "/" + s.Profile
+			part += "/" + bookmark.ProfileLabel(s.Profile, s.ProfileName)
 		}
 		parts = append(parts, part)
 	}
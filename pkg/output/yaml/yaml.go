@@ -75,19 +75,24 @@ func (a *Adapter) Render(collection *bookmark.Collection, opts output.RenderOpti
 		}
 		for _, s := range collection.Sources {
 			doc.Metadata.Sources = append(doc.Metadata.Sources, SourceEntry{
-				Name:    s.Name,
-				Profile: s.Profile,
-				Path:    s.Path,
-				Count:   s.Count,
+				Name:        s.Name,
+				Instance:    s.Instance,
+				Profile:     s.Profile,
+				ProfileName: s.ProfileName,
+				Path:        s.Path,
+				Count:       s.Count,
 			})
 		}
 	}
 
 	for _, b := range collection.Bookmarks {
 		entry := BookmarkEntry{
-			Title:  b.Title,
-			URL:    b.URL,
-			Folder: joinFolder(b.FolderPath),
+			Title:       b.Title,
+			URL:         b.URL,
+			Folder:      joinFolder(b.FolderPath),
+			Keyword:     b.Keyword,
+			Description: b.Description,
+			GUID:        b.GUID,
 		}
 
 		if opts.IncludeDates && !b.DateAdded.IsZero() {
@@ -102,6 +107,14 @@ func (a *Adapter) Render(collection *bookmark.Collection, opts output.RenderOpti
 		if opts.IncludeProfile {
 			entry.Source = b.Source
 			entry.Profile = b.Profile
+			entry.ProfileName = b.ProfileName
+			for _, o := range b.AlsoIn() {
+				entry.AlsoIn = append(entry.AlsoIn, OccurrenceEntry{
+					Source:  o.Source,
+					Profile: o.Profile,
+					Folder:  joinFolder(o.FolderPath),
+				})
+			}
 		}
 
 		doc.Bookmarks = append(doc.Bookmarks, entry)
@@ -137,19 +150,33 @@ type Metadata struct {
 
 // SourceEntry describes a bookmark source.
 type SourceEntry struct {
-	Name    string `yaml:"name"`
-	Profile string `yaml:"profile,omitempty"`
-	Path    string `yaml:"path,omitempty"`
-	Count   int    `yaml:"count"`
+	Name        string `yaml:"name"`
+	Instance    string `yaml:"instance,omitempty"`
+	Profile     string `yaml:"profile,omitempty"`
+	ProfileName string `yaml:"profile_name,omitempty"`
+	Path        string `yaml:"path,omitempty"`
+	Count       int    `yaml:"count"`
 }
 
 // BookmarkEntry is a single bookmark in the YAML output.
 type BookmarkEntry struct {
-	Title     string   `yaml:"title"`
-	URL       string   `yaml:"url"`
-	Folder    string   `yaml:"folder,omitempty"`
-	DateAdded string   `yaml:"date_added,omitempty"`
-	Tags      []string `yaml:"tags,omitempty"`
-	Source    string   `yaml:"source,omitempty"`
-	Profile   string   `yaml:"profile,omitempty"`
+	Title       string            `yaml:"title"`
+	URL         string            `yaml:"url"`
+	Folder      string            `yaml:"folder,omitempty"`
+	DateAdded   string            `yaml:"date_added,omitempty"`
+	Tags        []string          `yaml:"tags,omitempty"`
+	Keyword     string            `yaml:"keyword,omitempty"`
+	Description string            `yaml:"description,omitempty"`
+	GUID        string            `yaml:"guid,omitempty"`
+	Source      string            `yaml:"source,omitempty"`
+	Profile     string            `yaml:"profile,omitempty"`
+	ProfileName string            `yaml:"profile_name,omitempty"`
+	AlsoIn      []OccurrenceEntry `yaml:"also_in,omitempty"`
+}
+
+// OccurrenceEntry is another place a merged bookmark was found.
+type OccurrenceEntry struct {
+	Source  string `yaml:"source"`
+	Profile string `yaml:"profile,omitempty"`
+	Folder  string `yaml:"folder,omitempty"`
 }
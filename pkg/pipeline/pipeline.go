@@ -0,0 +1,765 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pipeline provides the read, filter, transform and render stages
+// that turn configured input adapters into rendered bookmark output.
+//
+// The CLI and the MCP server both build a Pipeline from config.Config,
+// so they always produce the same collection. Programs embedding favs
+// can do the same:
+//
+//	p := pipeline.New(cfg)
+//	collection, err := p.Run(ctx, pipeline.ReadOptions{All: true})
+//	if err != nil {
+//	    return err
+//	}
+//	data, err := p.Render(collection, "markdown", p.RenderOptions())
+//
+// Each stage can also be called on its own, and Hooks observe the
+// pipeline as it runs (for logging or progress reporting). Problems met
+// along the way, such as inputs that fail to read, are collected in
+// Diagnostics.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cloudygreybeard/favs/pkg/adapter"
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+	"github.com/cloudygreybeard/favs/pkg/config"
+	"github.com/cloudygreybeard/favs/pkg/diag"
+	"github.com/cloudygreybeard/favs/pkg/input"
+	"github.com/cloudygreybeard/favs/pkg/output"
+)
+
+// InputPreference is the order in which input adapters are tried when
+// no input is specified, and the order in which they are read in
+// all-inputs mode. Other registered adapters, such as opml, are read
+// after these in all-inputs mode when enabled.
+var InputPreference = []string{
+	"chrome", "firefox", "edge", "safari", "chromium", "brave",
+	"vivaldi", "opera", "opera-gx", "arc", "yandex",
+	"chrome-beta", "chrome-dev", "chrome-canary",
+	"librewolf", "waterfox", "floorp", "zen", "tor",
+}
+
+// ReadOptions selects which input adapters the read stage uses.
+type ReadOptions struct {
+	// All reads every enabled and available input, with all profiles.
+	All bool
+
+	// Input names a specific input adapter. Empty means the first
+	// enabled and available input in InputPreference order.
+	// Ignored when All is set.
+	Input string
+
+	// Profile selects a profile within the input. Empty means the
+	// configured profile, or "Default". Ignored when All is set.
+	Profile string
+}
+
+// Hooks are optional callbacks invoked as the pipeline runs.
+// Nil hooks are skipped. The read hooks may be called from several
+// goroutines at once, since inputs are read concurrently.
+type Hooks struct {
+	// BeforeRead is called before an input adapter is read.
+	BeforeRead func(inp input.Adapter)
+
+	// AfterRead is called after an input adapter has been read,
+	// with the bookmarks it returned or the error that occurred.
+	AfterRead func(inp input.Adapter, bookmarks []bookmark.Bookmark, err error)
+
+	// AfterReadDone is called after AfterRead with the same arguments
+	// and how long the read took, including a read that timed out.
+	AfterReadDone func(inp input.Adapter, bookmarks []bookmark.Bookmark, elapsed time.Duration, err error)
+
+	// AfterFilter is called with the result of the filter stage.
+	AfterFilter func(result bookmark.FilterResult)
+
+	// AfterTransform is called with the collection produced by the
+	// transform stage.
+	AfterTransform func(collection *bookmark.Collection)
+}
+
+// Pipeline reads, filters, transforms and renders bookmarks according
+// to a configuration.
+type Pipeline struct {
+	config config.Config
+	diags  diag.Diagnostics
+
+	tagOnce sync.Once
+	tagger  *bookmark.Tagger
+
+	folderOnce sync.Once
+	folders    *bookmark.FolderMapper
+
+	// Hooks observe the pipeline stages.
+	Hooks Hooks
+}
+
+// New creates a pipeline for the given configuration.
+func New(cfg config.Config) *Pipeline {
+	return &Pipeline{config: cfg}
+}
+
+// Diagnostics returns the problems recorded by the stages run so far.
+func (p *Pipeline) Diagnostics() *diag.Diagnostics {
+	return &p.diags
+}
+
+// Config returns the configuration the pipeline was built from.
+func (p *Pipeline) Config() config.Config {
+	return p.config
+}
+
+// Run reads bookmarks and applies the filter and transform stages.
+func (p *Pipeline) Run(ctx context.Context, opts ReadOptions) (*bookmark.Collection, error) {
+	collection, err := p.Read(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return p.Transform(p.Filter(collection)), nil
+}
+
+// Read collects bookmarks from the input adapters selected by opts.
+//
+// In all-inputs mode, inputs are read concurrently (see ReadInputs);
+// inputs that fail to configure or read, or time out, are skipped (and
+// reported through Hooks.AfterRead). Otherwise any failure is returned
+// as an error.
+func (p *Pipeline) Read(ctx context.Context, opts ReadOptions) (*bookmark.Collection, error) {
+	collection := bookmark.NewCollection()
+
+	if opts.All {
+		p.readAll(ctx, collection)
+		return collection, nil
+	}
+
+	if err := p.readOne(ctx, opts, collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+func (p *Pipeline) readOne(ctx context.Context, opts ReadOptions, collection *bookmark.Collection) error {
+	var target *source
+
+	if opts.Input != "" {
+		src, err := p.newSource(opts.Input)
+		if err != nil {
+			return err
+		}
+		target = src
+	} else {
+		// Find first available by preference
+		for _, name := range InputPreference {
+			if !p.config.GetInputConfig(name).Enabled {
+				continue
+			}
+			if src, err := p.newSource(name); err == nil && src.available() {
+				target = src
+				break
+			}
+		}
+	}
+
+	if target == nil {
+		return fmt.Errorf("no available browser found")
+	}
+
+	profile := opts.Profile
+	if profile == "" {
+		profile = target.config.Profile
+	}
+	if profile == "" {
+		profile = "Default"
+	}
+
+	if err := target.configure(profile); err != nil {
+		p.diags.Errorf(target.Name(), "config error: %v", err)
+		return fmt.Errorf("configuring %s: %w", target.Name(), err)
+	}
+
+	bookmarks, err := p.read(ctx, target)
+	if err != nil {
+		return fmt.Errorf("reading from %s: %w", target.Name(), err)
+	}
+
+	collection.Add(bookmarks, target.info(profile, bookmarks))
+	return nil
+}
+
+func (p *Pipeline) readAll(ctx context.Context, collection *bookmark.Collection) {
+	results, _ := p.ReadInputs(ctx, p.Inputs())
+	for _, c := range results {
+		if c == nil {
+			continue
+		}
+		collection.Bookmarks = append(collection.Bookmarks, c.Bookmarks...)
+		collection.Sources = append(collection.Sources, c.Sources...)
+	}
+}
+
+// ReadInputs reads several inputs as ReadInput does, at most
+// pipeline.read.workers at a time. Results and errors are returned in
+// the order of names, whatever order the reads finish in; the
+// collection of a failed input is nil.
+func (p *Pipeline) ReadInputs(ctx context.Context, names []string) ([]*bookmark.Collection, []error) {
+	results := make([]*bookmark.Collection, len(names))
+	errs := make([]error, len(names))
+
+	workers := p.config.Pipeline.Read.Workers
+	if workers <= 0 || workers > len(names) {
+		workers = len(names)
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = p.ReadInput(ctx, name)
+		}()
+	}
+	wg.Wait()
+
+	return results, errs
+}
+
+// Inputs returns the names of the enabled and available inputs that
+// all-inputs mode reads: built-in inputs in InputPreference order, the
+// other registered inputs by name, then the named sources in
+// configuration order. A named source with the
+// same name as a built-in input replaces it.
+func (p *Pipeline) Inputs() []string {
+	var names []string
+	for _, name := range p.sourceNames() {
+		if src, err := p.newSource(name); err == nil && src.available() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Input returns the input adapter or named source called name,
+// configured to read all its profiles as all-inputs mode does. Its Name
+// is the source name.
+func (p *Pipeline) Input(name string) (input.Adapter, error) {
+	src, err := p.newSource(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := src.configure(""); err != nil {
+		return nil, fmt.Errorf("configuring %s: %w", name, err)
+	}
+	return src, nil
+}
+
+// sourceNames returns the enabled built-in inputs and the named sources,
+// whether or not they are available.
+func (p *Pipeline) sourceNames() []string {
+	var names []string
+	builtin := append([]string(nil), InputPreference...)
+	for _, name := range adapter.ListInputs() {
+		if !slices.Contains(InputPreference, name) {
+			builtin = append(builtin, name)
+		}
+	}
+	for _, name := range builtin {
+		if _, ok := p.config.GetSource(name); ok {
+			continue
+		}
+		if p.config.GetInputConfig(name).Enabled {
+			names = append(names, name)
+		}
+	}
+	for _, src := range p.config.Sources {
+		names = append(names, src.Name)
+	}
+	return names
+}
+
+// ReadInput reads every profile of a single input adapter or named
+// source, as all-inputs mode does. A named source with a profile
+// configured reads only that profile. The collection has no sources if
+// the input returned no bookmarks.
+func (p *Pipeline) ReadInput(ctx context.Context, name string) (*bookmark.Collection, error) {
+	src, err := p.newSource(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Built-in inputs are configured without a profile to read them all
+	profile := ""
+	if src.instance != "" {
+		profile = src.config.Profile
+	}
+	if err := src.configure(profile); err != nil {
+		err = fmt.Errorf("config error: %w", err)
+		p.diags.Errorf(name, "%v", err)
+		p.afterRead(src, nil, 0, err)
+		return nil, err
+	}
+
+	bookmarks, err := p.read(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := bookmark.NewCollection()
+	if len(bookmarks) > 0 {
+		collection.Add(bookmarks, src.info(bookmarks[0].Profile, bookmarks))
+	}
+	return collection, nil
+}
+
+// read reads a configured source within the configured timeout,
+// attributing its bookmarks to the source instance if it has one.
+func (p *Pipeline) read(ctx context.Context, src *source) ([]bookmark.Bookmark, error) {
+	p.beforeRead(src)
+	start := time.Now()
+
+	ctx = diag.WithSource(diag.WithDiagnostics(ctx, &p.diags), src.Name())
+	bookmarks, err := p.readTimeout(ctx, src)
+	if err != nil {
+		p.diags.Errorf(src.Name(), "reading: %v", err)
+	} else if src.instance != "" {
+		for i := range bookmarks {
+			bookmarks[i].Source = src.instance
+		}
+	}
+
+	p.afterRead(src, bookmarks, time.Since(start), err)
+	return bookmarks, err
+}
+
+// readTimeout calls the adapter's Read under pipeline.read.timeout.
+// Adapters that don't check their context are abandoned when it
+// expires, and finish in the background.
+func (p *Pipeline) readTimeout(ctx context.Context, src *source) ([]bookmark.Bookmark, error) {
+	timeout := p.config.Pipeline.Read.Timeout
+	if timeout <= 0 {
+		return src.Read(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		bookmarks []bookmark.Bookmark
+		err       error
+	}
+	done := make(chan result, 1)
+	go func() {
+		bookmarks, err := src.Read(ctx)
+		done <- result{bookmarks, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil && ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("timed out after %s", timeout)
+		}
+		return r.bookmarks, r.err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("timed out after %s", timeout)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// WatchPaths returns the files backing the inputs read in all-inputs
+// mode, so long-running callers can re-run the pipeline when they change.
+func (p *Pipeline) WatchPaths() []string {
+	var paths []string
+	for _, name := range p.sourceNames() {
+		paths = append(paths, p.InputPaths(name)...)
+	}
+	return paths
+}
+
+// InputPaths returns the files backing one input adapter or named
+// source across all its profiles. Inputs that do not implement
+// input.Watchable contribute their Path().
+func (p *Pipeline) InputPaths(name string) []string {
+	src, err := p.newSource(name)
+	if err != nil || src.configure("") != nil {
+		return nil
+	}
+
+	if w, ok := src.Adapter.(input.Watchable); ok {
+		return w.WatchPaths()
+	}
+	if path := src.Path(); path != "" {
+		return []string{path}
+	}
+	return nil
+}
+
+// source is a freshly created input adapter: either a built-in input,
+// or a named source instance from the configuration. Name returns the
+// instance name for named sources.
+type source struct {
+	input.Adapter
+	name     string
+	instance string // empty for built-in inputs
+	config   config.InputConfig
+}
+
+// newSource creates an adapter for a named source or built-in input.
+func (p *Pipeline) newSource(name string) (*source, error) {
+	if sc, ok := p.config.GetSource(name); ok {
+		inp, ok := adapter.NewInput(sc.Adapter)
+		if !ok {
+			return nil, fmt.Errorf("source %s: unknown adapter: %s", name, sc.Adapter)
+		}
+		return &source{
+			Adapter:  inp,
+			name:     name,
+			instance: name,
+			config: config.InputConfig{
+				Enabled:    true,
+				Profile:    sc.Profile,
+				CustomPath: sc.Path,
+				Options:    sc.Options,
+			},
+		}, nil
+	}
+
+	inp, ok := adapter.NewInput(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown browser: %s", name)
+	}
+	return &source{Adapter: inp, name: name, config: p.config.GetInputConfig(name)}, nil
+}
+
+// Name returns the instance name, or the adapter name for built-in inputs.
+func (s *source) Name() string {
+	return s.name
+}
+
+func (s *source) configure(profile string) error {
+	return s.Configure(input.Config{
+		Enabled:    true,
+		Profile:    profile,
+		CustomPath: s.config.CustomPath,
+		Options:    s.config.Options,
+	})
+}
+
+// available configures the source for all profiles and reports whether
+// it can be read.
+func (s *source) available() bool {
+	return s.configure("") == nil && s.Available()
+}
+
+// info describes the source of bookmarks read for a profile. When the
+// bookmarks carry the name the user gave their profile, the profile is
+// recorded by its ID and name, so "-p Work" reads as "Profile 1".
+func (s *source) info(profile string, bookmarks []bookmark.Bookmark) bookmark.SourceInfo {
+	info := bookmark.SourceInfo{
+		Name:     s.Adapter.Name(),
+		Instance: s.instance,
+		Profile:  profile,
+		Path:     s.Path(),
+	}
+	if len(bookmarks) > 0 && bookmarks[0].ProfileName != "" {
+		info.Profile = bookmarks[0].Profile
+		info.ProfileName = bookmarks[0].ProfileName
+	}
+	return info
+}
+
+// Filter applies the configured filter rules to a collection.
+// The returned collection keeps the source information of the input.
+// Invalid rules are recorded as errors, suspicious bookmarks as warnings
+// and excluded bookmarks as info diagnostics.
+func (p *Pipeline) Filter(collection *bookmark.Collection) *bookmark.Collection {
+	result := bookmark.Filter(collection.Bookmarks, p.FilterOptions())
+	for _, msg := range result.Errors {
+		p.diags.Add(diag.Diagnostic{Severity: diag.Error, Message: "filter: " + msg})
+	}
+	for _, w := range result.WarningDetails {
+		b := w.Bookmark
+		p.diags.Add(diag.Diagnostic{Severity: diag.Warning, Source: b.Source, Profile: b.Profile, Bookmark: &b, Message: w.Message})
+	}
+	for _, e := range result.Exclusions {
+		b := e.Bookmark
+		p.diags.Add(diag.Diagnostic{Severity: diag.Info, Source: b.Source, Profile: b.Profile, Bookmark: &b, Message: e.Reason})
+	}
+	if p.Hooks.AfterFilter != nil {
+		p.Hooks.AfterFilter(result)
+	}
+
+	return &bookmark.Collection{
+		Bookmarks: result.Bookmarks,
+		Sources:   collection.Sources,
+	}
+}
+
+// Transform applies the configured transformations to a collection:
+// TransformBookmarks, then Merge. Sorting is left to the renderers (see
+// output.RenderOptions.SortAlpha).
+func (p *Pipeline) Transform(collection *bookmark.Collection) *bookmark.Collection {
+	return p.Merge(&bookmark.Collection{
+		Bookmarks: p.TransformBookmarks(collection.Bookmarks),
+		Sources:   collection.Sources,
+	})
+}
+
+// Merge completes Transform for bookmarks that already went through
+// TransformBookmarks, such as those in the search index: it merges
+// duplicates if deduplicate is set and calls the AfterTransform hook.
+func (p *Pipeline) Merge(collection *bookmark.Collection) *bookmark.Collection {
+	bookmarks := collection.Bookmarks
+	if p.config.Pipeline.Transform.Deduplicate {
+		bookmarks = bookmark.Merge(bookmarks, p.MergeOptions())
+	}
+
+	result := &bookmark.Collection{
+		Bookmarks: bookmarks,
+		Sources:   collection.Sources,
+	}
+	if p.Hooks.AfterTransform != nil {
+		p.Hooks.AfterTransform(result)
+	}
+	return result
+}
+
+// TransformBookmarks applies the transformations that work on one
+// bookmark at a time: URL cleaning, folder remapping and tagging.
+// Transform applies them before Merge; the search index stores their
+// result.
+func (p *Pipeline) TransformBookmarks(bookmarks []bookmark.Bookmark) []bookmark.Bookmark {
+	bookmarks = p.CleanURLs(bookmarks)
+	if m := p.getFolderMapper(); m != nil {
+		bookmarks = m.Map(bookmarks)
+	}
+	if t := p.getTagger(); t != nil {
+		bookmarks = t.Tag(bookmarks)
+	}
+	return bookmarks
+}
+
+// getFolderMapper compiles the configured folder remapping on first
+// use, recording rename rules that cannot be compiled. It returns nil if
+// no remapping is configured.
+func (p *Pipeline) getFolderMapper() *bookmark.FolderMapper {
+	cfg := p.config.Pipeline.Transform.Folders
+	if !cfg.NormalizeRoots && len(cfg.Rename) == 0 && cfg.MaxDepth <= 0 {
+		return nil
+	}
+	p.folderOnce.Do(func() {
+		opts := bookmark.FolderOptions{NormalizeRoots: cfg.NormalizeRoots, MaxDepth: cfg.MaxDepth}
+		for _, r := range cfg.Rename {
+			opts.Rename = append(opts.Rename, bookmark.FolderRename{From: r.From, To: r.To})
+		}
+		var errs []string
+		p.folders, errs = bookmark.NewFolderMapper(opts)
+		for _, msg := range errs {
+			p.diags.Add(diag.Diagnostic{Severity: diag.Error, Message: "folders: " + msg})
+		}
+	})
+	return p.folders
+}
+
+// getTagger compiles the configured tag rules on first use, recording
+// rules that cannot be compiled. It returns nil if tagging is disabled.
+func (p *Pipeline) getTagger() *bookmark.Tagger {
+	cfg := p.config.Pipeline.Transform.Tagging
+	if !cfg.Enabled {
+		return nil
+	}
+	p.tagOnce.Do(func() {
+		var rules []bookmark.TagRule
+		if cfg.Builtin {
+			rules = append(rules, bookmark.BuiltinTagRules...)
+		}
+		for i, r := range cfg.Rules {
+			rule := filterRule(r.FilterRule)
+			if rule.ID == "" {
+				rule.ID = fmt.Sprintf("rules[%d]", i)
+			}
+			rules = append(rules, bookmark.TagRule{Rule: rule, Add: r.Add})
+		}
+		var errs []string
+		p.tagger, errs = bookmark.NewTagger(rules)
+		for _, msg := range errs {
+			p.diags.Add(diag.Diagnostic{Severity: diag.Error, Message: "tagging: " + msg})
+		}
+	})
+	return p.tagger
+}
+
+// canonicalizer returns the configured URL canonicalizer, or nil if
+// canonicalization is disabled.
+func (p *Pipeline) canonicalizer() *bookmark.Canonicalizer {
+	c := p.config.Pipeline.Transform.Canonicalize
+	if !c.Enabled {
+		return nil
+	}
+	return &bookmark.Canonicalizer{StripParams: c.StripParams, SortQuery: c.SortQuery}
+}
+
+// URLKey returns the key bookmarks are deduplicated by: the canonical
+// form of the URL, or the URL itself if canonicalization is disabled.
+func (p *Pipeline) URLKey(rawURL string) string {
+	if c := p.canonicalizer(); c != nil {
+		return c.Key(rawURL)
+	}
+	return rawURL
+}
+
+// CleanURLs returns the bookmarks with cleaned URLs if rewrite_urls is
+// set, and the bookmarks unchanged otherwise.
+func (p *Pipeline) CleanURLs(bookmarks []bookmark.Bookmark) []bookmark.Bookmark {
+	c := p.canonicalizer()
+	if c == nil || !p.config.Pipeline.Transform.Canonicalize.RewriteURLs {
+		return bookmarks
+	}
+	result := make([]bookmark.Bookmark, len(bookmarks))
+	for i, b := range bookmarks {
+		b.URL = c.Clean(b.URL)
+		result[i] = b
+	}
+	return result
+}
+
+// Render renders a collection with the named output adapter.
+func (p *Pipeline) Render(collection *bookmark.Collection, format string, opts output.RenderOptions) ([]byte, error) {
+	outAdapter, ok := adapter.GetOutput(format)
+	if !ok {
+		return nil, fmt.Errorf("unknown output format: %s (available: %v)", format, adapter.ListOutputs())
+	}
+
+	// Output adapters are shared, so configure and render in one step
+	renderMu.Lock()
+	defer renderMu.Unlock()
+
+	if err := outAdapter.Configure(p.outputConfig(format)); err != nil {
+		return nil, fmt.Errorf("configuring %s: %w", format, err)
+	}
+
+	data, err := outAdapter.Render(collection, opts)
+	if err != nil {
+		return nil, fmt.Errorf("rendering output: %w", err)
+	}
+	return data, nil
+}
+
+// renderMu serialises output adapter configuration and rendering.
+var renderMu sync.Mutex
+
+// outputConfig builds the runtime config of an output adapter. The
+// style setting is passed as the "style" option unless options set it.
+func (p *Pipeline) outputConfig(name string) output.Config {
+	outputCfg := p.config.GetOutputConfig(name)
+	options := make(map[string]interface{}, len(outputCfg.Options)+1)
+	if outputCfg.Style != "" {
+		options["style"] = outputCfg.Style
+	}
+	for k, v := range outputCfg.Options {
+		options[k] = v
+	}
+	return output.Config{Enabled: outputCfg.Enabled, Options: options}
+}
+
+// MergeOptions returns the options Merge merges duplicates with when
+// deduplicate is set.
+func (p *Pipeline) MergeOptions() bookmark.MergeOptions {
+	t := p.config.Pipeline.Transform
+	return bookmark.MergeOptions{
+		Strategy:      t.Merge,
+		PreferSources: t.PreferSources,
+		Key:           p.URLKey,
+	}
+}
+
+// FilterOptions returns the filter options derived from the configuration.
+func (p *Pipeline) FilterOptions() bookmark.FilterOptions {
+	f := p.config.Pipeline.Filter
+	return bookmark.FilterOptions{
+		IncludeFolders:     f.IncludeFolders,
+		ExcludeFolders:     f.ExcludeFolders,
+		ExcludeURLPatterns: f.ExcludeURLPatterns,
+		ExcludeProtocols:   f.ExcludeProtocols,
+		WarnProtocols:      f.WarnProtocols,
+		MaxURLLength:       f.MaxURLLength,
+		WarnURLLength:      f.WarnURLLength,
+		Rules:              filterRules(f.Rules),
+	}
+}
+
+// filterRules converts configured filter rules to bookmark rules.
+func filterRules(rules []config.FilterRule) []bookmark.Rule {
+	var result []bookmark.Rule
+	for _, r := range rules {
+		result = append(result, filterRule(r))
+	}
+	return result
+}
+
+// filterRule converts a configured filter rule to a bookmark rule.
+func filterRule(r config.FilterRule) bookmark.Rule {
+	return bookmark.Rule{
+		ID:          r.ID,
+		Action:      r.Action,
+		Folder:      r.Folder,
+		Domains:     r.Domains,
+		Tags:        r.Tags,
+		Title:       r.Title,
+		URL:         r.URL,
+		AddedBefore: r.AddedBefore,
+		AddedAfter:  r.AddedAfter,
+		Any:         filterRules(r.Any),
+		All:         filterRules(r.All),
+	}
+}
+
+// RenderOptions returns the render options derived from the configuration.
+// Callers may adjust the result (for example Style) before rendering.
+func (p *Pipeline) RenderOptions() output.RenderOptions {
+	r := p.config.Pipeline.Render
+	return output.RenderOptions{
+		IncludeMetadata: r.IncludeMetadata,
+		IncludeDates:    r.IncludeDates,
+		IncludeTags:     r.IncludeTags,
+		IncludeProfile:  r.IncludeProfile,
+		GroupBySource:   r.GroupBySource,
+		SortAlpha:       p.config.Pipeline.Transform.Sort,
+	}
+}
+
+func (p *Pipeline) beforeRead(inp input.Adapter) {
+	if p.Hooks.BeforeRead != nil {
+		p.Hooks.BeforeRead(inp)
+	}
+}
+
+func (p *Pipeline) afterRead(inp input.Adapter, bookmarks []bookmark.Bookmark, elapsed time.Duration, err error) {
+	if p.Hooks.AfterRead != nil {
+		p.Hooks.AfterRead(inp, bookmarks, err)
+	}
+	if p.Hooks.AfterReadDone != nil {
+		p.Hooks.AfterReadDone(inp, bookmarks, elapsed, err)
+	}
+}
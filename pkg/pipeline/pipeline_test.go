@@ -0,0 +1,375 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudygreybeard/favs/pkg/adapter"
+	"github.com/cloudygreybeard/favs/pkg/bookmark"
+	"github.com/cloudygreybeard/favs/pkg/config"
+	"github.com/cloudygreybeard/favs/pkg/diag"
+	"github.com/cloudygreybeard/favs/pkg/input"
+	_ "github.com/cloudygreybeard/favs/pkg/input/opml"
+	_ "github.com/cloudygreybeard/favs/pkg/output/json"
+)
+
+// fakeInput is an input adapter returning fixed bookmarks.
+type fakeInput struct {
+	name      string
+	bookmarks []bookmark.Bookmark
+	config    input.Config
+}
+
+func (f *fakeInput) Name() string                   { return f.name }
+func (f *fakeInput) DisplayName() string            { return "Fake " + f.name }
+func (f *fakeInput) Available() bool                { return true }
+func (f *fakeInput) Path() string                   { return "/fake/" + f.name }
+func (f *fakeInput) Configure(c input.Config) error { f.config = c; return nil }
+
+func (f *fakeInput) ListProfiles() ([]input.ProfileInfo, error) { return nil, nil }
+
+func (f *fakeInput) Read(ctx context.Context) ([]bookmark.Bookmark, error) {
+	return f.bookmarks, nil
+}
+
+// fake stands in for the first input in InputPreference.
+var fake = &fakeInput{
+	name: "chrome",
+	bookmarks: []bookmark.Bookmark{
+		{Title: "Go", URL: "https://go.dev/", FolderPath: []string{"Dev"}, Source: "chrome", Profile: "Default"},
+		{Title: "Go again", URL: "https://go.dev/", FolderPath: []string{"Other"}, Source: "chrome", Profile: "Default"},
+		{Title: "Old", URL: "https://old.example.com/", FolderPath: []string{"Trash"}, Source: "chrome", Profile: "Default"},
+		{Title: "Pixel", URL: "data:image/png;base64,AAAA", Source: "chrome", Profile: "Default"},
+		{Title: "Notes", URL: "file:///home/me/notes.txt", Source: "chrome", Profile: "Default"},
+	},
+}
+
+func init() {
+	adapter.RegisterInput(fake)
+	adapter.RegisterInputFactory(func() input.Adapter { return &slowAdapter{} })
+}
+
+func titles(c *bookmark.Collection) string {
+	var t []string
+	for _, b := range c.Bookmarks {
+		t = append(t, b.Title)
+	}
+	return strings.Join(t, ",")
+}
+
+func TestRead(t *testing.T) {
+	p := New(config.Default())
+
+	var before, after []string
+	p.Hooks.BeforeRead = func(inp input.Adapter) { before = append(before, inp.Name()) }
+	p.Hooks.AfterRead = func(inp input.Adapter, bookmarks []bookmark.Bookmark, err error) {
+		after = append(after, inp.Name())
+	}
+
+	c, err := p.Read(context.Background(), ReadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Count() != len(fake.bookmarks) || len(c.Sources) != 1 {
+		t.Fatalf("read %d bookmarks from %d sources", c.Count(), len(c.Sources))
+	}
+	if s := c.Sources[0]; s.Name != "chrome" || s.Profile != "Default" || s.Path != "/fake/chrome" {
+		t.Errorf("source = %+v", s)
+	}
+	if fake.config.Profile != "Default" {
+		t.Errorf("configured profile = %q, want Default", fake.config.Profile)
+	}
+	if strings.Join(before, ",") != "chrome" || strings.Join(after, ",") != "chrome" {
+		t.Errorf("hooks saw %v before and %v after reading", before, after)
+	}
+
+	if _, err := p.Read(context.Background(), ReadOptions{Input: "nope"}); err == nil {
+		t.Error("reading an unknown input succeeded")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	p := New(config.Default())
+	var result bookmark.FilterResult
+	p.Hooks.AfterFilter = func(r bookmark.FilterResult) { result = r }
+
+	in := &bookmark.Collection{Bookmarks: fake.bookmarks, Sources: []bookmark.SourceInfo{{Name: "chrome"}}}
+	c := p.Filter(in)
+	if got := titles(c); got != "Go,Go again,Notes" {
+		t.Errorf("filtered = %s, want Go,Go again,Notes", got)
+	}
+	if len(c.Sources) != 1 {
+		t.Errorf("sources not kept: %+v", c.Sources)
+	}
+	if result.Excluded != 2 || len(result.Warnings) != 1 {
+		t.Errorf("AfterFilter saw %d excluded and warnings %v", result.Excluded, result.Warnings)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	cfg := config.Default()
+	in := &bookmark.Collection{Bookmarks: fake.bookmarks[:2]}
+
+	if got := titles(New(cfg).Transform(in)); got != "Go,Go again" {
+		t.Errorf("without deduplicate = %s", got)
+	}
+
+	cfg.Pipeline.Transform.Deduplicate = true
+	p := New(cfg)
+	var seen *bookmark.Collection
+	p.Hooks.AfterTransform = func(c *bookmark.Collection) { seen = c }
+	c := p.Transform(in)
+	if got := titles(c); got != "Go" {
+		t.Errorf("with deduplicate = %s, want Go", got)
+	}
+	if seen != c {
+		t.Error("AfterTransform not called with the result")
+	}
+}
+
+func TestRender(t *testing.T) {
+	p := New(config.Default())
+	c := &bookmark.Collection{Bookmarks: fake.bookmarks[:1]}
+
+	data, err := p.Render(c, "json", p.RenderOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "https://go.dev/") {
+		t.Errorf("rendered output lacks the bookmark: %s", data)
+	}
+
+	if _, err := p.Render(c, "nope", p.RenderOptions()); err == nil {
+		t.Error("rendering an unknown format succeeded")
+	}
+}
+
+// slowAdapter returns one bookmark after the delay in its options,
+// ignoring its context.
+type slowAdapter struct {
+	delay time.Duration
+}
+
+func (a *slowAdapter) Name() string                               { return "slow" }
+func (a *slowAdapter) DisplayName() string                        { return "Slow" }
+func (a *slowAdapter) Available() bool                            { return true }
+func (a *slowAdapter) Path() string                               { return "" }
+func (a *slowAdapter) ListProfiles() ([]input.ProfileInfo, error) { return nil, nil }
+
+func (a *slowAdapter) Configure(cfg input.Config) error {
+	a.delay, _ = time.ParseDuration(cfg.CustomPath)
+	return nil
+}
+
+func (a *slowAdapter) Read(ctx context.Context) ([]bookmark.Bookmark, error) {
+	time.Sleep(a.delay)
+	return []bookmark.Bookmark{{Title: "slow", URL: "https://example.com/"}}, nil
+}
+
+func writeOPML(t *testing.T, dir, name, url string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	data := `<opml version="2.0"><body><outline text="` + name + `" htmlUrl="` + url + `"/></body></opml>`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRead_NamedSources(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Config{
+		Sources: []config.SourceConfig{
+			{Name: "work-export", Adapter: "opml", Path: writeOPML(t, dir, "work.opml", "https://work.example.com/")},
+			{Name: "home-export", Adapter: "opml", Path: writeOPML(t, dir, "home.opml", "https://home.example.com/")},
+			{Name: "missing", Adapter: "opml"},
+		},
+	}
+	p := New(cfg)
+
+	if got := p.Inputs(); len(got) != 2 || got[0] != "work-export" || got[1] != "home-export" {
+		t.Fatalf("Inputs = %v, want both exports and not the unconfigured source", got)
+	}
+
+	c, err := p.Read(context.Background(), ReadOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Count() != 2 || len(c.Sources) != 2 {
+		t.Fatalf("read %d bookmarks from %d sources", c.Count(), len(c.Sources))
+	}
+	for i, want := range []string{"work-export", "home-export"} {
+		if s := c.Sources[i]; s.Name != "opml" || s.Instance != want || s.ID() != want {
+			t.Errorf("source %d = %+v, want opml instance %s", i, s, want)
+		}
+		if b := c.Bookmarks[i]; b.Source != want {
+			t.Errorf("bookmark %d source = %s, want %s", i, b.Source, want)
+		}
+	}
+
+	one, err := p.Read(context.Background(), ReadOptions{Input: "home-export"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if one.Count() != 1 || one.Bookmarks[0].URL != "https://home.example.com/" {
+		t.Errorf("reading one source = %+v", one.Bookmarks)
+	}
+
+	if inp, err := p.Input("work-export"); err != nil || inp.Name() != "work-export" || inp.Path() != cfg.Sources[0].Path {
+		t.Errorf("Input(work-export) = %v, %v", inp, err)
+	}
+
+	if paths := p.WatchPaths(); len(paths) != 2 {
+		t.Errorf("WatchPaths = %v", paths)
+	}
+	if _, err := p.ReadInput(context.Background(), "nope"); err == nil {
+		t.Error("reading an unknown source succeeded")
+	}
+}
+
+func TestInputs_OtherAdapters(t *testing.T) {
+	cfg := config.Config{Inputs: map[string]config.InputConfig{
+		"chrome": {Enabled: true},
+		"opml":   {Enabled: true, CustomPath: writeOPML(t, t.TempDir(), "export.opml", "https://example.com/")},
+	}}
+	if got := New(cfg).Inputs(); len(got) != 2 || got[0] != "chrome" || got[1] != "opml" {
+		t.Errorf("Inputs = %v, want chrome then the enabled opml input", got)
+	}
+}
+
+func TestRead_Parallel(t *testing.T) {
+	cfg := config.Config{
+		Pipeline: config.PipelineConfig{
+			Read: config.ReadConfig{Workers: 3, Timeout: 200 * time.Millisecond},
+		},
+		Sources: []config.SourceConfig{
+			{Name: "a", Adapter: "slow", Path: "100ms"},
+			{Name: "b", Adapter: "slow", Path: "10ms"},
+			{Name: "stuck", Adapter: "slow", Path: "10s"},
+			{Name: "c", Adapter: "slow", Path: "50ms"},
+		},
+	}
+	p := New(cfg)
+
+	var mu sync.Mutex
+	var failed []string
+	var stuckFor time.Duration
+	p.Hooks.AfterRead = func(inp input.Adapter, _ []bookmark.Bookmark, err error) {
+		if err != nil {
+			mu.Lock()
+			failed = append(failed, inp.Name()+": "+err.Error())
+			mu.Unlock()
+		}
+	}
+	p.Hooks.AfterReadDone = func(inp input.Adapter, _ []bookmark.Bookmark, elapsed time.Duration, err error) {
+		if inp.Name() == "stuck" {
+			mu.Lock()
+			stuckFor = elapsed
+			mu.Unlock()
+		}
+	}
+
+	start := time.Now()
+	c, err := p.Read(context.Background(), ReadOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("read took %s; the stuck source was not abandoned", elapsed)
+	}
+
+	var order []string
+	for _, s := range c.Sources {
+		order = append(order, s.ID())
+	}
+	if got := strings.Join(order, ","); got != "a,b,c" {
+		t.Errorf("sources = %s, want a,b,c in configuration order", got)
+	}
+	if len(failed) != 1 || !strings.HasPrefix(failed[0], "stuck: timed out") {
+		t.Errorf("failures = %v, want the stuck source to time out", failed)
+	}
+	if stuckFor < 200*time.Millisecond || stuckFor > time.Second {
+		t.Errorf("AfterReadDone saw the stuck source take %s, want the 200ms timeout", stuckFor)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	cfg := config.Config{
+		Sources: []config.SourceConfig{
+			{Name: "ok", Adapter: "opml", Path: writeOPML(t, t.TempDir(), "ok.opml", "javascript:void(0)")},
+			{Name: "gone", Adapter: "opml", Path: "/nonexistent/bookmarks.opml"},
+		},
+		Pipeline: config.PipelineConfig{
+			Filter: config.FilterConfig{
+				ExcludeURLPatterns: []string{"("},
+				ExcludeProtocols:   []string{"javascript"},
+			},
+		},
+	}
+	p := New(cfg)
+
+	if _, err := p.Run(context.Background(), ReadOptions{All: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	all := p.Diagnostics().All()
+	if len(all) != 3 {
+		t.Fatalf("diagnostics = %v, want 3", all)
+	}
+	if d := all[0]; d.Severity != diag.Error || d.Source != "gone" {
+		t.Errorf("read failure = %+v", d)
+	}
+	if d := all[1]; d.Severity != diag.Error || !strings.Contains(d.Message, "invalid URL pattern") {
+		t.Errorf("invalid pattern = %+v", d)
+	}
+	if d := all[2]; d.Severity != diag.Info || d.Source != "ok" || d.Bookmark == nil {
+		t.Errorf("exclusion = %+v", d)
+	}
+}
+
+func TestTransform_Canonicalize(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pipeline.Transform.Deduplicate = true
+	collection := &bookmark.Collection{Bookmarks: []bookmark.Bookmark{
+		{Title: "A", URL: "https://example.com/page?utm_source=feed&id=1"},
+		{Title: "B", URL: "http://www.example.com/page/?id=1"},
+	}}
+
+	got := New(cfg).Transform(collection).Bookmarks
+	if len(got) != 1 || got[0].URL != "https://example.com/page?utm_source=feed&id=1" {
+		t.Errorf("deduplicated = %v, want A with its original URL", got)
+	}
+
+	cfg.Pipeline.Transform.Canonicalize.RewriteURLs = true
+	got = New(cfg).Transform(collection).Bookmarks
+	if len(got) != 1 || got[0].URL != "https://example.com/page?id=1" {
+		t.Errorf("rewritten = %v", got)
+	}
+	if collection.Bookmarks[0].URL != "https://example.com/page?utm_source=feed&id=1" {
+		t.Error("Transform modified its input")
+	}
+
+	cfg.Pipeline.Transform.Canonicalize.Enabled = false
+	if got = New(cfg).Transform(collection).Bookmarks; len(got) != 2 {
+		t.Errorf("without canonicalization = %v, want both", got)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package watch detects changes to bookmark files.
+//
+// Browsers replace or rewrite their bookmark stores in different ways
+// (atomic renames for Chromium, WAL appends for Firefox), so the watcher
+// polls file metadata rather than relying on platform notification APIs.
+// The poll interval doubles as a debounce for bursts of writes.
+package watch
+
+import (
+	"context"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// fileState is the metadata compared between polls.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func stat(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+// equal compares the modification times as instants, ignoring their
+// location and monotonic clock reading.
+func (s fileState) equal(o fileState) bool {
+	return s.exists == o.exists && s.size == o.size && s.modTime.Equal(o.modTime)
+}
+
+// Watcher polls a set of files for changes.
+type Watcher struct {
+	interval time.Duration
+
+	mu    sync.Mutex
+	files map[string]fileState
+}
+
+// New creates a watcher that polls every interval.
+func New(interval time.Duration) *Watcher {
+	return &Watcher{
+		interval: interval,
+		files:    make(map[string]fileState),
+	}
+}
+
+// Set replaces the watched files. Files that were already watched keep
+// their last known state; new files are recorded as they are now.
+// Missing files are watched too, so their creation is reported.
+func (w *Watcher) Set(paths []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	files := make(map[string]fileState, len(paths))
+	for _, p := range paths {
+		if state, ok := w.files[p]; ok {
+			files[p] = state
+		} else {
+			files[p] = stat(p)
+		}
+	}
+	w.files = files
+}
+
+// Poll checks every watched file once and returns the paths that were
+// created, removed or modified since the previous check, sorted.
+func (w *Watcher) Poll() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var changed []string
+	for p, prev := range w.files {
+		cur := stat(p)
+		if !cur.equal(prev) {
+			w.files[p] = cur
+			changed = append(changed, p)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Run polls until ctx is cancelled, calling onChange with the paths that
+// changed in each poll.
+func (w *Watcher) Run(ctx context.Context, onChange func(changed []string)) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if changed := w.Poll(); len(changed) > 0 {
+				onChange(changed)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 cloudygreybeard
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher_Poll(t *testing.T) {
+	dir := t.TempDir()
+	bookmarks := filepath.Join(dir, "Bookmarks")
+	wal := filepath.Join(dir, "places.sqlite-wal")
+
+	if err := os.WriteFile(bookmarks, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := New(time.Second)
+	w.Set([]string{bookmarks, wal})
+
+	if changed := w.Poll(); len(changed) != 0 {
+		t.Fatalf("unexpected changes before any write: %v", changed)
+	}
+
+	// Rewrite with a different size, and create the missing file
+	if err := os.WriteFile(bookmarks, []byte(`{"roots":{}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(wal, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := w.Poll()
+	if len(changed) != 2 || changed[0] != bookmarks || changed[1] != wal {
+		t.Fatalf("changed = %v, want [%s %s]", changed, bookmarks, wal)
+	}
+
+	if changed := w.Poll(); len(changed) != 0 {
+		t.Errorf("changes reported twice: %v", changed)
+	}
+
+	if err := os.Remove(wal); err != nil {
+		t.Fatal(err)
+	}
+	if changed := w.Poll(); len(changed) != 1 || changed[0] != wal {
+		t.Errorf("removal not reported: %v", changed)
+	}
+}
+
+func TestFileState_Equal(t *testing.T) {
+	now := time.Now()
+	a := fileState{exists: true, size: 2, modTime: now}
+	if b := (fileState{exists: true, size: 2, modTime: now.Round(0).UTC()}); !a.equal(b) {
+		t.Error("the same instant in another location compared unequal")
+	}
+	if b := (fileState{exists: true, size: 3, modTime: now}); a.equal(b) {
+		t.Error("different sizes compared equal")
+	}
+}